@@ -95,7 +95,7 @@ jobs:
 func SetTestPipeline(pipelineName string, configFilePath string) error {
 	var err error
 	var setOutput []byte
-	setOutput, err = flyCommand.SetPipeline(pipelineName, configFilePath, nil, nil)
+	setOutput, err = flyCommand.SetPipeline(pipelineName, configFilePath, nil, nil, false)
 	fmt.Fprintf(GinkgoWriter, "pipeline '%s' set; output:\n\n%s\n", pipelineName, string(setOutput))
 	return err
 }
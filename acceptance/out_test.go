@@ -144,7 +144,7 @@ jobs:
 			},
 			Params: concourse.OutParams{
 				Pipelines:     pipelines,
-				PipelinesFile: pipelinesFileFilename,
+				PipelinesFile: []string{pipelinesFileFilename},
 			},
 		}
 
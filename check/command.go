@@ -62,15 +62,18 @@ func (c *Command) Run(input concourse.CheckRequest) (concourse.CheckResponse, er
 		}
 	}
 
-	teams := make(map[string]concourse.Team)
+	pipelineVersions := make(map[string]string)
 
+	// Teams are checked in the order of source, so that the version of a
+	// pipeline name shared by several teams is always that of the last.
+	seenTeams := make(map[string]bool)
 	for _, team := range input.Source.Teams {
-		teams[team.Name] = team
-	}
-
-	pipelineVersions := make(map[string]string)
+		teamName := team.Name
+		if seenTeams[teamName] {
+			continue
+		}
+		seenTeams[teamName] = true
 
-	for teamName, team := range teams {
 		c.logger.Debugf("Performing login\n")
 		_, err := c.flyCommand.Login(
 			input.Source.Target,
@@ -93,7 +96,7 @@ func (c *Command) Run(input concourse.CheckRequest) (concourse.CheckResponse, er
 
 		for _, pipelineName := range pipelines {
 			c.logger.Debugf("Getting pipeline: %s\n", pipelineName)
-			outBytes, err := c.flyCommand.GetPipeline(pipelineName)
+			outBytes, err := c.flyCommand.GetPipeline(pipelineName, false)
 			if err != nil {
 				return concourse.CheckResponse{}, err
 			}
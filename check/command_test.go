@@ -52,7 +52,7 @@ pipeline1: foo
 pipeline2: foo
 `
 
-		fakeFlyCommand.GetPipelineStub = func(name string) ([]byte, error) {
+		fakeFlyCommand.GetPipelineStub = func(name string, asJSON bool) ([]byte, error) {
 			ginkgoLogger.Debugf("GetPipelineStub for: %s\n", name)
 
 			switch name {
@@ -125,6 +125,36 @@ pipeline2: foo
 		Expect(response).To(Equal(expectedResponse))
 	})
 
+	Context("when two teams have a pipeline with the same name", func() {
+		BeforeEach(func() {
+			checkRequest.Source.Teams = []concourse.Team{
+				{Name: "main"},
+				{Name: "other"},
+			}
+
+			var currentTeam string
+			fakeFlyCommand.LoginStub = func(_ string, teamName string, _ string, _ string, _ bool) ([]byte, error) {
+				currentTeam = teamName
+				return nil, nil
+			}
+			fakeFlyCommand.GetPipelineStub = func(name string, asJSON bool) ([]byte, error) {
+				return []byte(fmt.Sprintf("team: %s\n", currentTeam)), nil
+			}
+		})
+
+		It("returns the version of the pipeline of the last team", func() {
+			for i := 0; i < 10; i++ {
+				response, err := command.Run(checkRequest)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(response).To(Equal(concourse.CheckResponse{{
+					pipelines[0]: fmt.Sprintf("%x", md5.Sum([]byte("team: other\n"))),
+					pipelines[1]: fmt.Sprintf("%x", md5.Sum([]byte("team: other\n"))),
+				}}))
+			}
+		})
+	})
+
 	Context("when the most recent version is provided", func() {
 		BeforeEach(func() {
 			checkRequest.Version = concourse.Version{
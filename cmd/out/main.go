@@ -8,8 +8,8 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/concourse/concourse-pipeline-resource/cmd/out/filereader"
 	"github.com/concourse/concourse-pipeline-resource/concourse"
+	"github.com/concourse/concourse-pipeline-resource/filereader"
 	"github.com/concourse/concourse-pipeline-resource/fly"
 	"github.com/concourse/concourse-pipeline-resource/logger"
 	"github.com/concourse/concourse-pipeline-resource/out"
@@ -73,18 +73,29 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	if input.Params.PipelinesFile != "" {
-		pipelinesFromFile, err := filereader.PipelinesFromFile(input.Params.PipelinesFile, sourcesDir)
+	if len(input.Params.PipelinesFile) > 0 {
+		pipelinesFromFiles, err := filereader.PipelinesFromFiles(input.Params.PipelinesFile, sourcesDir)
 		if err != nil {
 			l.Debugf("Exiting with error: %v\n", err)
 			log.Fatalln(err)
 		}
 
-		input.Params.PipelinesFile = ""
-		input.Params.Pipelines = pipelinesFromFile
+		input.Params.PipelinesFile = nil
+		input.Params.Pipelines = pipelinesFromFiles
 	}
 
-	// Validate contents of pipelines file
+	if input.Params.PipelinesDir != "" {
+		pipelinesFromDir, err := filereader.PipelinesFromDir(input.Params.PipelinesDir, sourcesDir)
+		if err != nil {
+			l.Debugf("Exiting with error: %v\n", err)
+			log.Fatalln(err)
+		}
+
+		input.Params.PipelinesDir = ""
+		input.Params.Pipelines = pipelinesFromDir
+	}
+
+	// Validate contents of pipelines file or dir
 	err = validator.ValidateOut(input)
 	if err != nil {
 		l.Debugf("Exiting with error: %v\n", err)
@@ -0,0 +1,148 @@
+package concourse
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AppendSuffix marks a key whose list is appended to the inherited list,
+// e.g. vars_files+, rather than replacing it.
+const AppendSuffix = "+"
+
+// PipelineDefaults are the settings inherited by every pipeline in a
+// manifest. TeamDefaults override Defaults for the pipelines of a team.
+type PipelineDefaults struct {
+	Defaults     map[string]interface{}            `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	TeamDefaults map[string]map[string]interface{} `json:"team_defaults,omitempty" yaml:"team_defaults,omitempty"`
+}
+
+// Apply merges each pipeline entry over the defaults for its team. Maps are
+// merged recursively, while lists and other values replace those inherited,
+// unless their key ends with AppendSuffix.
+func (d PipelineDefaults) Apply(entries []map[string]interface{}) ([]map[string]interface{}, error) {
+	for _, key := range []string{"name", "renamed_from"} {
+		if _, found := d.Defaults[key]; found {
+			return nil, fmt.Errorf("%s cannot be provided in %s", key, "defaults")
+		}
+	}
+
+	for teamName, teamDefaults := range d.TeamDefaults {
+		for _, key := range []string{"name", "renamed_from", "team"} {
+			if _, found := teamDefaults[key]; found {
+				return nil, fmt.Errorf("%s cannot be provided in %s", key, "team_defaults."+teamName)
+			}
+		}
+	}
+
+	pipelines := make([]map[string]interface{}, len(entries))
+	for i, entry := range entries {
+		p := map[string]interface{}{}
+
+		err := merge(p, d.Defaults)
+		if err != nil {
+			return nil, fmt.Errorf("defaults: %s", err)
+		}
+
+		teamName, _ := entry["team"].(string)
+		if teamName == "" {
+			teamName, _ = p["team"].(string)
+		}
+
+		if teamDefaults, found := d.TeamDefaults[teamName]; found {
+			err = merge(p, teamDefaults)
+			if err != nil {
+				return nil, fmt.Errorf("team_defaults.%s: %s", teamName, err)
+			}
+		}
+
+		err = merge(p, entry)
+		if err != nil {
+			return nil, fmt.Errorf("pipelines[%d]: %s", i, err)
+		}
+
+		pipelines[i] = p
+	}
+
+	return pipelines, nil
+}
+
+// merge merges src into dst without modifying any map or list in src, so
+// that defaults can be merged into many pipelines.
+func merge(dst map[string]interface{}, src map[string]interface{}) error {
+	keys := make([]string, 0, len(src))
+	for k := range src {
+		keys = append(keys, k)
+	}
+
+	// Replaced values are merged before those appended to, so that an entry
+	// can both replace and append to a list regardless of key order.
+	sort.Slice(keys, func(i, j int) bool {
+		iAppend := strings.HasSuffix(keys[i], AppendSuffix)
+		jAppend := strings.HasSuffix(keys[j], AppendSuffix)
+		if iAppend != jAppend {
+			return jAppend
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, k := range keys {
+		v := src[k]
+
+		if strings.HasSuffix(k, AppendSuffix) {
+			key := strings.TrimSuffix(k, AppendSuffix)
+
+			list, ok := v.([]interface{})
+			if !ok && v != nil {
+				return fmt.Errorf("%s must be a list", k)
+			}
+
+			existing, ok := dst[key].([]interface{})
+			if !ok && dst[key] != nil {
+				return fmt.Errorf("%s must be a list to be appended to with %s", key, k)
+			}
+
+			dst[key] = append(append([]interface{}{}, existing...), list...)
+			continue
+		}
+
+		srcMap, ok := asMap(v)
+		if !ok {
+			dst[k] = v
+			continue
+		}
+
+		m := map[string]interface{}{}
+		if existing, ok := asMap(dst[k]); ok {
+			for ek, ev := range existing {
+				m[ek] = ev
+			}
+		}
+
+		err := merge(m, srcMap)
+		if err != nil {
+			return fmt.Errorf("%s.%s", k, err)
+		}
+
+		dst[k] = m
+	}
+
+	return nil
+}
+
+// asMap returns the map as decoded from either JSON or YAML, the latter of
+// which has interface{} keys for nested maps.
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			converted[fmt.Sprintf("%v", k)] = v
+		}
+		return converted, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,199 @@
+package concourse_test
+
+import (
+	"github.com/concourse/concourse-pipeline-resource/concourse"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PipelineDefaults", func() {
+	var (
+		defaults concourse.PipelineDefaults
+		entries  []map[string]interface{}
+	)
+
+	BeforeEach(func() {
+		defaults = concourse.PipelineDefaults{
+			Defaults: map[string]interface{}{
+				"team":       "main",
+				"unpaused":   true,
+				"vars_files": []interface{}{"common.yml"},
+				"vars": map[interface{}]interface{}{
+					"slack": map[interface{}]interface{}{
+						"channel": "#ci",
+						"icon":    ":concourse:",
+					},
+				},
+			},
+			TeamDefaults: map[string]map[string]interface{}{
+				"other": {
+					"exposed":     true,
+					"vars_files+": []interface{}{"other.yml"},
+				},
+			},
+		}
+
+		entries = []map[string]interface{}{
+			{
+				"name":        "a",
+				"config_file": "a.yml",
+			},
+			{
+				"name":        "b",
+				"team":        "other",
+				"config_file": "b.yml",
+				"vars_files+": []interface{}{"b.yml"},
+				"vars": map[interface{}]interface{}{
+					"slack": map[interface{}]interface{}{
+						"channel": "#other",
+					},
+				},
+			},
+			{
+				"name":        "c",
+				"config_file": "c.yml",
+				"unpaused":    false,
+				"vars_files":  []interface{}{"c.yml"},
+			},
+		}
+	})
+
+	It("merges each entry over the defaults for its team", func() {
+		pipelines, err := defaults.Apply(entries)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(pipelines).To(Equal([]map[string]interface{}{
+			{
+				"name":        "a",
+				"team":        "main",
+				"config_file": "a.yml",
+				"unpaused":    true,
+				"vars_files":  []interface{}{"common.yml"},
+				"vars": map[string]interface{}{
+					"slack": map[string]interface{}{
+						"channel": "#ci",
+						"icon":    ":concourse:",
+					},
+				},
+			},
+			{
+				"name":        "b",
+				"team":        "other",
+				"config_file": "b.yml",
+				"unpaused":    true,
+				"exposed":     true,
+				"vars_files":  []interface{}{"common.yml", "other.yml", "b.yml"},
+				"vars": map[string]interface{}{
+					"slack": map[string]interface{}{
+						"channel": "#other",
+						"icon":    ":concourse:",
+					},
+				},
+			},
+			{
+				"name":        "c",
+				"team":        "main",
+				"config_file": "c.yml",
+				"unpaused":    false,
+				"vars_files":  []interface{}{"c.yml"},
+				"vars": map[string]interface{}{
+					"slack": map[string]interface{}{
+						"channel": "#ci",
+						"icon":    ":concourse:",
+					},
+				},
+			},
+		}))
+	})
+
+	It("does not modify the defaults", func() {
+		_, err := defaults.Apply(entries)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(defaults.Defaults["vars_files"]).To(Equal([]interface{}{"common.yml"}))
+		Expect(defaults.Defaults["vars"]).To(Equal(map[interface{}]interface{}{
+			"slack": map[interface{}]interface{}{
+				"channel": "#ci",
+				"icon":    ":concourse:",
+			},
+		}))
+	})
+
+	It("applies a list appended to when nothing is inherited", func() {
+		pipelines, err := concourse.PipelineDefaults{}.Apply([]map[string]interface{}{
+			{"name": "a", "vars_files+": []interface{}{"a.yml"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(pipelines).To(Equal([]map[string]interface{}{
+			{"name": "a", "vars_files": []interface{}{"a.yml"}},
+		}))
+	})
+
+	It("replaces a list before appending to it", func() {
+		pipelines, err := defaults.Apply([]map[string]interface{}{
+			{
+				"name":        "a",
+				"vars_files+": []interface{}{"b.yml"},
+				"vars_files":  []interface{}{"a.yml"},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(pipelines[0]["vars_files"]).To(Equal([]interface{}{"a.yml", "b.yml"}))
+	})
+
+	Context("when a value which is not a list is appended", func() {
+		BeforeEach(func() {
+			entries[0]["vars_files+"] = "a.yml"
+		})
+
+		It("returns an error", func() {
+			_, err := defaults.Apply(entries)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(Equal("pipelines[0]: vars_files+ must be a list"))
+		})
+	})
+
+	Context("when a value which is not a list is appended to", func() {
+		BeforeEach(func() {
+			entries[1]["vars"] = map[interface{}]interface{}{
+				"slack+": []interface{}{"#other"},
+			}
+		})
+
+		It("returns an error", func() {
+			_, err := defaults.Apply(entries)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(Equal("pipelines[1]: vars.slack must be a list to be appended to with slack+"))
+		})
+	})
+
+	Context("when the name is provided in defaults", func() {
+		BeforeEach(func() {
+			defaults.Defaults["name"] = "a"
+		})
+
+		It("returns an error", func() {
+			_, err := defaults.Apply(entries)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(Equal("name cannot be provided in defaults"))
+		})
+	})
+
+	Context("when the team is provided in team defaults", func() {
+		BeforeEach(func() {
+			defaults.TeamDefaults["other"]["team"] = "main"
+		})
+
+		It("returns an error", func() {
+			_, err := defaults.Apply(entries)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(Equal("team cannot be provided in team_defaults.other"))
+		})
+	})
+})
@@ -0,0 +1,147 @@
+package concourse
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const matrixKey = "matrix"
+
+// ExpandMatrix replaces each pipeline entry with a matrix by a pipeline for
+// every combination of the values in its matrix. References to a matrix key,
+// e.g. ((env)), in the entry are replaced by the value of the combination,
+// which is also added to the vars of the pipeline.
+func ExpandMatrix(entries []map[string]interface{}) ([]map[string]interface{}, error) {
+	pipelines := []map[string]interface{}{}
+	for i, entry := range entries {
+		m, found := entry[matrixKey]
+		if !found {
+			pipelines = append(pipelines, entry)
+			continue
+		}
+
+		expanded, err := expandEntry(entry, m)
+		if err != nil {
+			return nil, fmt.Errorf("pipelines[%d]: %s", i, err)
+		}
+
+		pipelines = append(pipelines, expanded...)
+	}
+
+	return pipelines, nil
+}
+
+func expandEntry(entry map[string]interface{}, m interface{}) ([]map[string]interface{}, error) {
+	matrix, ok := asMap(m)
+	if !ok || len(matrix) == 0 {
+		return nil, fmt.Errorf("%s must be a non-empty map", matrixKey)
+	}
+
+	keys := make([]string, 0, len(matrix))
+	for k, values := range matrix {
+		list, ok := values.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, fmt.Errorf("%s.%s must be a non-empty list", matrixKey, k)
+		}
+
+		for j, v := range list {
+			switch v.(type) {
+			case string, bool, int, int64, float64:
+			default:
+				return nil, fmt.Errorf("%s.%s[%d] must be a string, number or boolean", matrixKey, k, j)
+			}
+		}
+
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	vars, ok := asMap(entry["vars"])
+	if !ok && entry["vars"] != nil {
+		return nil, fmt.Errorf("%s must be a map", "vars")
+	}
+
+	for _, k := range keys {
+		if _, found := vars[k]; found {
+			return nil, fmt.Errorf("vars.%s cannot be provided as it is a %s key", k, matrixKey)
+		}
+	}
+
+	// Combinations are ordered by the keys of the matrix, with the values of
+	// the last key varying fastest.
+	combinations := []map[string]interface{}{{}}
+	for _, k := range keys {
+		var next []map[string]interface{}
+		for _, c := range combinations {
+			for _, v := range matrix[k].([]interface{}) {
+				combination := map[string]interface{}{k: v}
+				for ck, cv := range c {
+					combination[ck] = cv
+				}
+				next = append(next, combination)
+			}
+		}
+		combinations = next
+	}
+
+	pipelines := make([]map[string]interface{}, 0, len(combinations))
+	seen := map[string]bool{}
+	for _, combination := range combinations {
+		replacements := make([]string, 0, 2*len(combination))
+		for k, v := range combination {
+			replacements = append(replacements, "(("+k+"))", fmt.Sprintf("%v", v))
+		}
+		replacer := strings.NewReplacer(replacements...)
+
+		p := map[string]interface{}{}
+		for k, v := range entry {
+			if k == matrixKey || k == "vars" {
+				continue
+			}
+			p[k] = interpolate(v, replacer)
+		}
+
+		pipelineVars := map[string]interface{}{}
+		for k, v := range vars {
+			pipelineVars[k] = interpolate(v, replacer)
+		}
+		for k, v := range combination {
+			pipelineVars[k] = v
+		}
+		p["vars"] = pipelineVars
+
+		key := fmt.Sprintf("%v/%v", p["team"], p["name"])
+		if seen[key] {
+			return nil, fmt.Errorf("%s expands to pipeline '%s' more than once", matrixKey, key)
+		}
+		seen[key] = true
+
+		pipelines = append(pipelines, p)
+	}
+
+	return pipelines, nil
+}
+
+// interpolate returns a copy of v with the replacer applied to every string.
+func interpolate(v interface{}, replacer *strings.Replacer) interface{} {
+	switch t := v.(type) {
+	case string:
+		return replacer.Replace(t)
+	case []interface{}:
+		list := make([]interface{}, len(t))
+		for i, e := range t {
+			list[i] = interpolate(e, replacer)
+		}
+		return list
+	case map[string]interface{}, map[interface{}]interface{}:
+		m, _ := asMap(t)
+		interpolated := make(map[string]interface{}, len(m))
+		for k, e := range m {
+			interpolated[k] = interpolate(e, replacer)
+		}
+		return interpolated
+	default:
+		return v
+	}
+}
@@ -0,0 +1,158 @@
+package concourse_test
+
+import (
+	"github.com/concourse/concourse-pipeline-resource/concourse"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExpandMatrix", func() {
+	var entries []map[string]interface{}
+
+	BeforeEach(func() {
+		entries = []map[string]interface{}{
+			{
+				"name":        "build",
+				"team":        "main",
+				"config_file": "build.yml",
+			},
+			{
+				"name":        "deploy-((env))-((region))",
+				"team":        "main",
+				"config_file": "deploy.yml",
+				"vars_files":  []interface{}{"vars/((env)).yml"},
+				"vars": map[interface{}]interface{}{
+					"url":   "https://((region)).((env)).example.com",
+					"token": "((deploy_token))",
+				},
+				"matrix": map[interface{}]interface{}{
+					"env":    []interface{}{"dev", "prod"},
+					"region": []interface{}{"eu", "us"},
+				},
+			},
+		}
+	})
+
+	It("expands each entry with a matrix into a pipeline per combination", func() {
+		pipelines, err := concourse.ExpandMatrix(entries)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(pipelines).To(HaveLen(5))
+		Expect(pipelines[0]).To(Equal(entries[0]))
+
+		deploy := func(env string, region string) map[string]interface{} {
+			return map[string]interface{}{
+				"name":        "deploy-" + env + "-" + region,
+				"team":        "main",
+				"config_file": "deploy.yml",
+				"vars_files":  []interface{}{"vars/" + env + ".yml"},
+				"vars": map[string]interface{}{
+					"url":    "https://" + region + "." + env + ".example.com",
+					"token":  "((deploy_token))",
+					"env":    env,
+					"region": region,
+				},
+			}
+		}
+
+		Expect(pipelines[1:]).To(Equal([]map[string]interface{}{
+			deploy("dev", "eu"),
+			deploy("dev", "us"),
+			deploy("prod", "eu"),
+			deploy("prod", "us"),
+		}))
+	})
+
+	It("keeps the type of values in the vars", func() {
+		entries[1]["matrix"] = map[string]interface{}{
+			"env":      []interface{}{"dev"},
+			"region":   []interface{}{"eu"},
+			"replicas": []interface{}{1, 3},
+		}
+		entries[1]["name"] = "deploy-((replicas))"
+
+		pipelines, err := concourse.ExpandMatrix(entries)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(pipelines).To(HaveLen(3))
+		Expect(pipelines[1]["name"]).To(Equal("deploy-1"))
+		Expect(pipelines[1]["vars"]).To(HaveKeyWithValue("replicas", 1))
+		Expect(pipelines[2]["name"]).To(Equal("deploy-3"))
+		Expect(pipelines[2]["vars"]).To(HaveKeyWithValue("replicas", 3))
+	})
+
+	It("does not modify the entries", func() {
+		_, err := concourse.ExpandMatrix(entries)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(entries[1]["name"]).To(Equal("deploy-((env))-((region))"))
+		Expect(entries[1]["vars_files"]).To(Equal([]interface{}{"vars/((env)).yml"}))
+	})
+
+	Context("when the matrix expands to the same pipeline more than once", func() {
+		BeforeEach(func() {
+			entries[1]["name"] = "deploy-((env))"
+		})
+
+		It("returns an error", func() {
+			_, err := concourse.ExpandMatrix(entries)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(Equal("pipelines[1]: matrix expands to pipeline 'main/deploy-dev' more than once"))
+		})
+	})
+
+	Context("when a matrix key is also provided in vars", func() {
+		BeforeEach(func() {
+			entries[1]["vars"] = map[string]interface{}{"env": "staging"}
+		})
+
+		It("returns an error", func() {
+			_, err := concourse.ExpandMatrix(entries)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(Equal("pipelines[1]: vars.env cannot be provided as it is a matrix key"))
+		})
+	})
+
+	Context("when the values of a matrix key are not a list", func() {
+		BeforeEach(func() {
+			entries[1]["matrix"] = map[string]interface{}{"env": "dev"}
+		})
+
+		It("returns an error", func() {
+			_, err := concourse.ExpandMatrix(entries)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(Equal("pipelines[1]: matrix.env must be a non-empty list"))
+		})
+	})
+
+	Context("when a value of a matrix key is not a scalar", func() {
+		BeforeEach(func() {
+			entries[1]["matrix"] = map[string]interface{}{
+				"env": []interface{}{"dev", []interface{}{"prod"}},
+			}
+		})
+
+		It("returns an error", func() {
+			_, err := concourse.ExpandMatrix(entries)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(Equal("pipelines[1]: matrix.env[1] must be a string, number or boolean"))
+		})
+	})
+
+	Context("when the matrix is empty", func() {
+		BeforeEach(func() {
+			entries[1]["matrix"] = map[string]interface{}{}
+		})
+
+		It("returns an error", func() {
+			_, err := concourse.ExpandMatrix(entries)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(Equal("pipelines[1]: matrix must be a non-empty map"))
+		})
+	})
+})
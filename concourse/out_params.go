@@ -0,0 +1,66 @@
+package concourse
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// UnmarshalJSON allows pipelines_file to be provided either as a single path
+// or as a list of paths and glob patterns, and applies any defaults and
+// matrices to the pipelines.
+func (p *OutParams) UnmarshalJSON(b []byte) error {
+	type outParams OutParams
+	aux := struct {
+		*outParams
+		PipelineDefaults
+		Pipelines     []map[string]interface{} `json:"pipelines,omitempty"`
+		PipelinesFile json.RawMessage          `json:"pipelines_file,omitempty"`
+	}{
+		outParams: (*outParams)(p),
+	}
+
+	err := json.Unmarshal(b, &aux)
+	if err != nil {
+		return err
+	}
+
+	if aux.Pipelines != nil {
+		entries, err := aux.PipelineDefaults.Apply(aux.Pipelines)
+		if err != nil {
+			return err
+		}
+
+		entries, err = ExpandMatrix(entries)
+		if err != nil {
+			return err
+		}
+
+		b, err := json.Marshal(entries)
+		if err != nil {
+			return err
+		}
+
+		err = json.Unmarshal(b, &p.Pipelines)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(aux.PipelinesFile) == 0 || string(aux.PipelinesFile) == "null" {
+		return nil
+	}
+
+	var file string
+	if json.Unmarshal(aux.PipelinesFile, &file) == nil {
+		p.PipelinesFile = []string{file}
+		return nil
+	}
+
+	var files []string
+	if json.Unmarshal(aux.PipelinesFile, &files) == nil {
+		p.PipelinesFile = files
+		return nil
+	}
+
+	return fmt.Errorf("%s must be a string or a list of strings", "pipelines_file")
+}
@@ -0,0 +1,117 @@
+package concourse_test
+
+import (
+	"encoding/json"
+
+	"github.com/concourse/concourse-pipeline-resource/concourse"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OutParams", func() {
+	var params concourse.OutParams
+
+	BeforeEach(func() {
+		params = concourse.OutParams{}
+	})
+
+	It("decodes a single pipelines file as a list", func() {
+		err := json.Unmarshal([]byte(`{"pipelines_file":"pipelines.yml","check_creds":true}`), &params)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(params.PipelinesFile).To(Equal([]string{"pipelines.yml"}))
+		Expect(params.CheckCreds).To(BeTrue())
+	})
+
+	It("decodes a single glob pattern as a list", func() {
+		err := json.Unmarshal([]byte(`{"pipelines_file":"manifests/*.yml"}`), &params)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(params.PipelinesFile).To(Equal([]string{"manifests/*.yml"}))
+	})
+
+	It("decodes a list of pipelines files", func() {
+		err := json.Unmarshal([]byte(`{"pipelines_file":["a.yml","products/*.yml"]}`), &params)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(params.PipelinesFile).To(Equal([]string{"a.yml", "products/*.yml"}))
+	})
+
+	It("decodes params without a pipelines file", func() {
+		err := json.Unmarshal([]byte(`{"pipelines_dir":"pipelines"}`), &params)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(params.PipelinesFile).To(BeNil())
+		Expect(params.PipelinesDir).To(Equal("pipelines"))
+	})
+
+	It("returns an error when the pipelines file is neither a string nor a list", func() {
+		err := json.Unmarshal([]byte(`{"pipelines_file":{"a":"b"}}`), &params)
+		Expect(err).To(HaveOccurred())
+
+		Expect(err.Error()).To(MatchRegexp(".*pipelines_file.*string.*list"))
+	})
+
+	It("applies defaults to the pipelines", func() {
+		err := json.Unmarshal([]byte(`{
+			"defaults": {"team": "main", "unpaused": true, "vars_files": ["common.yml"]},
+			"team_defaults": {"other": {"vars": {"registry": "other.example.com"}}},
+			"pipelines": [
+				{"name": "a", "config_file": "a.yml", "vars_files+": ["a.yml"]},
+				{"name": "b", "team": "other", "config_file": "b.yml", "unpaused": false}
+			]
+		}`), &params)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(params.Pipelines).To(Equal([]concourse.Pipeline{
+			{
+				Name:       "a",
+				TeamName:   "main",
+				ConfigFile: "a.yml",
+				VarsFiles:  []string{"common.yml", "a.yml"},
+				Unpaused:   true,
+			},
+			{
+				Name:       "b",
+				TeamName:   "other",
+				ConfigFile: "b.yml",
+				VarsFiles:  []string{"common.yml"},
+				Vars:       map[string]interface{}{"registry": "other.example.com"},
+			},
+		}))
+	})
+
+	It("returns an error when the defaults cannot be applied", func() {
+		err := json.Unmarshal([]byte(`{
+			"pipelines": [{"name": "a", "config_file": "a.yml", "vars_files+": "a.yml"}]
+		}`), &params)
+		Expect(err).To(HaveOccurred())
+
+		Expect(err.Error()).To(Equal("pipelines[0]: vars_files+ must be a list"))
+	})
+
+	It("expands the matrix of a pipeline", func() {
+		err := json.Unmarshal([]byte(`{
+			"defaults": {"team": "main"},
+			"pipelines": [
+				{"name": "deploy-((env))", "config_file": "deploy.yml", "matrix": {"env": ["dev", "prod"]}}
+			]
+		}`), &params)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(params.Pipelines).To(Equal([]concourse.Pipeline{
+			{
+				Name:       "deploy-dev",
+				TeamName:   "main",
+				ConfigFile: "deploy.yml",
+				Vars:       map[string]interface{}{"env": "dev"},
+			},
+			{
+				Name:       "deploy-prod",
+				TeamName:   "main",
+				ConfigFile: "deploy.yml",
+				Vars:       map[string]interface{}{"env": "prod"},
+			},
+		}))
+	})
+})
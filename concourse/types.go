@@ -7,9 +7,18 @@ type Source struct {
 }
 
 type Team struct {
-	Name     string `json:"name"`
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Name      string                 `json:"name"`
+	Username  string                 `json:"username"`
+	Password  string                 `json:"password"`
+	VarsFiles []string               `json:"vars_files,omitempty"`
+	Vars      map[string]interface{} `json:"vars,omitempty"`
+}
+
+// SharedVars are vars files and vars shared by many pipelines, such as those
+// of a team.
+type SharedVars struct {
+	VarsFiles []string
+	Vars      map[string]interface{}
 }
 
 type CheckRequest struct {
@@ -28,6 +37,23 @@ type InRequest struct {
 }
 
 type InParams struct {
+	Format             string   `json:"format,omitempty"`
+	Strict             bool     `json:"strict,omitempty"`
+	Layout             string   `json:"layout,omitempty"`
+	Teams              []string `json:"teams,omitempty"`
+	Pipelines          []string `json:"pipelines,omitempty"`
+	Redact             bool     `json:"redact,omitempty"`
+	RedactKeys         []string `json:"redact_keys,omitempty"`
+	RedactPatterns     []string `json:"redact_patterns,omitempty"`
+	Archive            bool     `json:"archive,omitempty"`
+	Inventory          bool     `json:"inventory,omitempty"`
+	Docs               bool     `json:"docs,omitempty"`
+	Graph              bool     `json:"graph,omitempty"`
+	CrossPipelineGraph bool     `json:"cross_pipeline_graph,omitempty"`
+	Normalize          bool     `json:"normalize,omitempty"`
+	Split              bool     `json:"split,omitempty"`
+	ExpectedManifest   string   `json:"expected_manifest,omitempty"`
+	FailOnDrift        bool     `json:"fail_on_drift,omitempty"`
 }
 
 type InResponse struct {
@@ -46,18 +72,30 @@ type OutRequest struct {
 }
 
 type OutParams struct {
-	Pipelines     []Pipeline `json:"pipelines,omitempty"`
-	PipelinesFile string     `json:"pipelines_file,omitempty"`
+	Pipelines        []Pipeline             `json:"pipelines,omitempty"`
+	PipelinesFile    []string               `json:"pipelines_file,omitempty"`
+	PipelinesDir     string                 `json:"pipelines_dir,omitempty"`
+	VarsFiles        []string               `json:"vars_files,omitempty"`
+	Vars             map[string]interface{} `json:"vars,omitempty"`
+	VarsFromEnv      map[string]string      `json:"vars_from_env,omitempty"`
+	CheckCreds       bool                   `json:"check_creds,omitempty"`
+	ValidateOnly     bool                   `json:"validate_only,omitempty"`
+	StrictValidation bool                   `json:"strict_validation,omitempty"`
 }
 
 type Pipeline struct {
-	Name       string                 `json:"name" yaml:"name"`
-	ConfigFile string                 `json:"config_file" yaml:"config_file"`
-	VarsFiles  []string               `json:"vars_files" yaml:"vars_files"`
-	Vars       map[string]interface{} `json:"vars" yaml:"vars"`
-	TeamName   string                 `json:"team" yaml:"team"`
-	Unpaused   bool                   `json:"unpaused" yaml:"unpaused"`
-	Exposed    bool                   `json:"exposed" yaml:"exposed"`
+	Name         string                 `json:"name" yaml:"name"`
+	RenamedFrom  string                 `json:"renamed_from" yaml:"renamed_from"`
+	ConfigFile   string                 `json:"config_file" yaml:"config_file"`
+	ConfigDir    string                 `json:"config_dir" yaml:"config_dir"`
+	VarsFiles    []string               `json:"vars_files" yaml:"vars_files"`
+	Vars         map[string]interface{} `json:"vars" yaml:"vars"`
+	VarsFromEnv  map[string]string      `json:"vars_from_env" yaml:"vars_from_env,omitempty"`
+	TeamName     string                 `json:"team" yaml:"team"`
+	Unpaused     bool                   `json:"unpaused" yaml:"unpaused"`
+	Exposed      bool                   `json:"exposed" yaml:"exposed"`
+	CheckCreds   bool                   `json:"check_creds" yaml:"check_creds"`
+	ValidateOnly bool                   `json:"validate_only" yaml:"validate_only"`
 }
 
 type OutResponse struct {
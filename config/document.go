@@ -0,0 +1,91 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Parse decodes a pipeline config in yaml or json, preserving the order of
+// the keys of every mapping so that it can be written back out unchanged.
+func Parse(b []byte) (yaml.MapSlice, error) {
+	var doc yaml.MapSlice
+	err := yaml.Unmarshal(b, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// Marshal encodes a pipeline config, or part of one, as yaml or, if asJSON is
+// true, as indented json.
+func Marshal(v interface{}, asJSON bool) ([]byte, error) {
+	if !asJSON {
+		return yaml.Marshal(v)
+	}
+
+	compact := bytes.NewBuffer(nil)
+	err := writeJSON(compact, v)
+	if err != nil {
+		return nil, err
+	}
+
+	indented := bytes.NewBuffer(nil)
+	err = json.Indent(indented, compact.Bytes(), "", "  ")
+	if err != nil {
+		// Untested as writeJSON always produces valid json
+		return nil, err
+	}
+	indented.WriteByte('\n')
+
+	return indented.Bytes(), nil
+}
+
+func writeJSON(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		buf.WriteByte('{')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			key, err := json.Marshal(fmt.Sprint(item.Key))
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+
+			err = writeJSON(buf, item.Value)
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			err := writeJSON(buf, item)
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+
+	return nil
+}
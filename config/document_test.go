@@ -0,0 +1,48 @@
+package config_test
+
+import (
+	"github.com/concourse/concourse-pipeline-resource/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Document", func() {
+	It("round trips yaml preserving the order of keys", func() {
+		doc, err := config.Parse([]byte("resources:\n- name: repo\n  type: git\njobs: []\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		b, err := config.Marshal(doc, false)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(string(b)).To(Equal("resources:\n- name: repo\n  type: git\njobs: []\n"))
+	})
+
+	It("marshals json preserving the order of keys", func() {
+		doc, err := config.Parse([]byte(`{"resources": [{"name": "repo", "source": {"depth": 1, "tag": null}}], "groups": []}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		b, err := config.Marshal(doc, true)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(string(b)).To(Equal(`{
+  "resources": [
+    {
+      "name": "repo",
+      "source": {
+        "depth": 1,
+        "tag": null
+      }
+    }
+  ],
+  "groups": []
+}
+`))
+	})
+
+	Context("when the config is invalid", func() {
+		It("returns an error", func() {
+			_, err := config.Parse([]byte("{{"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
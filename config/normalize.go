@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// topLevelOrder is the order in which the top-level sections of a
+// normalized pipeline config appear. Any others follow in sorted order.
+var topLevelOrder = []string{
+	"display",
+	"var_sources",
+	"resource_types",
+	"resources",
+	"jobs",
+	"groups",
+}
+
+// leadingKeys appear first, in this order, in every mapping within a
+// normalized pipeline config, as they identify the resource, job or step
+// they are part of. Other keys follow in sorted order.
+var leadingKeys = []string{
+	"name",
+	"get",
+	"put",
+	"task",
+	"set_pipeline",
+	"load_var",
+	"in_parallel",
+	"aggregate",
+	"do",
+	"try",
+}
+
+// Normalize returns the config with its top-level sections in a fixed order
+// and the keys of every mapping within them sorted, so that configs which
+// differ only in the order of keys are normalized identically. The order of
+// sequences is significant, so is left unchanged.
+func Normalize(doc yaml.MapSlice) yaml.MapSlice {
+	normalized := make(yaml.MapSlice, len(doc))
+	for i, item := range doc {
+		normalized[i] = yaml.MapItem{
+			Key:   item.Key,
+			Value: normalizeValue(item.Value),
+		}
+	}
+
+	sortKeys(normalized, topLevelOrder)
+	return normalized
+}
+
+func normalizeValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		normalized := make(yaml.MapSlice, len(v))
+		for i, item := range v {
+			normalized[i] = yaml.MapItem{
+				Key:   item.Key,
+				Value: normalizeValue(item.Value),
+			}
+		}
+
+		sortKeys(normalized, leadingKeys)
+		return normalized
+	case []interface{}:
+		normalized := make([]interface{}, len(v))
+		for i, item := range v {
+			normalized[i] = normalizeValue(item)
+		}
+		return normalized
+	default:
+		return v
+	}
+}
+
+// sortKeys sorts the mapping by the position of each key in order, with
+// keys which are not in order following in sorted order.
+func sortKeys(m yaml.MapSlice, order []string) {
+	rank := func(key interface{}) int {
+		for i, k := range order {
+			if k == key {
+				return i
+			}
+		}
+		return len(order)
+	}
+
+	sort.SliceStable(m, func(i, j int) bool {
+		ri, rj := rank(m[i].Key), rank(m[j].Key)
+		if ri != rj {
+			return ri < rj
+		}
+		return fmt.Sprint(m[i].Key) < fmt.Sprint(m[j].Key)
+	})
+}
@@ -0,0 +1,71 @@
+package config_test
+
+import (
+	"github.com/concourse/concourse-pipeline-resource/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Normalize", func() {
+	normalize := func(contents string) string {
+		doc, err := config.Parse([]byte(contents))
+		Expect(err).NotTo(HaveOccurred())
+
+		b, err := config.Marshal(config.Normalize(doc), false)
+		Expect(err).NotTo(HaveOccurred())
+
+		return string(b)
+	}
+
+	It("orders top-level sections and sorts keys with identifying keys first", func() {
+		Expect(normalize(`groups:
+- jobs: [build]
+  name: all
+jobs:
+- plan:
+  - trigger: true
+    get: repo
+    passed: [test]
+  - task: unit
+    file: repo/ci/unit.yml
+  serial: true
+  name: build
+resources:
+- type: git
+  source: {uri: example.com, branch: main}
+  name: repo
+custom: true
+`)).To(Equal(`resources:
+- name: repo
+  source:
+    branch: main
+    uri: example.com
+  type: git
+jobs:
+- name: build
+  plan:
+  - get: repo
+    passed:
+    - test
+    trigger: true
+  - task: unit
+    file: repo/ci/unit.yml
+  serial: true
+groups:
+- name: all
+  jobs:
+  - build
+custom: true
+`))
+	})
+
+	It("normalizes configs differing only in key order identically", func() {
+		Expect(normalize("jobs: [{name: a, plan: []}]\nresources: []\n")).To(Equal(
+			normalize("resources: []\njobs: [{plan: [], name: a}]\n"),
+		))
+	})
+
+	It("does not reorder sequences", func() {
+		Expect(normalize("jobs: [{name: b}, {name: a}]\n")).To(Equal("jobs:\n- name: b\n- name: a\n"))
+	})
+})
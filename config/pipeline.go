@@ -0,0 +1,201 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Pipeline is the subset of a pipeline config which is inspected by the
+// resource; other fields are ignored.
+type Pipeline struct {
+	ResourceTypes []ResourceType `yaml:"resource_types"`
+	Resources     []Resource     `yaml:"resources"`
+	Jobs          []Job          `yaml:"jobs"`
+	Groups        []Group        `yaml:"groups"`
+}
+
+type ResourceType struct {
+	Name   string                 `yaml:"name"`
+	Type   string                 `yaml:"type"`
+	Source map[string]interface{} `yaml:"source"`
+}
+
+type Resource struct {
+	Name   string                 `yaml:"name"`
+	Type   string                 `yaml:"type"`
+	Icon   string                 `yaml:"icon"`
+	Source map[string]interface{} `yaml:"source"`
+}
+
+type Group struct {
+	Name      string   `yaml:"name"`
+	Jobs      []string `yaml:"jobs"`
+	Resources []string `yaml:"resources"`
+}
+
+type Job struct {
+	Name      string `yaml:"name"`
+	Serial    bool   `yaml:"serial"`
+	Public    bool   `yaml:"public"`
+	Plan      []Step `yaml:"plan"`
+	OnSuccess *Step  `yaml:"on_success"`
+	OnFailure *Step  `yaml:"on_failure"`
+	OnAbort   *Step  `yaml:"on_abort"`
+	OnError   *Step  `yaml:"on_error"`
+	Ensure    *Step  `yaml:"ensure"`
+}
+
+// Step is a step of a job's plan, along with any steps nested within it.
+type Step struct {
+	Get         string   `yaml:"get"`
+	Put         string   `yaml:"put"`
+	Task        string   `yaml:"task"`
+	SetPipeline string   `yaml:"set_pipeline"`
+	Resource    string   `yaml:"resource"`
+	Trigger     bool     `yaml:"trigger"`
+	Passed      []string `yaml:"passed"`
+
+	File   string      `yaml:"file"`
+	Image  string      `yaml:"image"`
+	Config *TaskConfig `yaml:"config"`
+
+	Do         []Step      `yaml:"do"`
+	InParallel *InParallel `yaml:"in_parallel"`
+	Aggregate  []Step      `yaml:"aggregate"`
+	Try        *Step       `yaml:"try"`
+
+	OnSuccess *Step `yaml:"on_success"`
+	OnFailure *Step `yaml:"on_failure"`
+	OnAbort   *Step `yaml:"on_abort"`
+	OnError   *Step `yaml:"on_error"`
+	Ensure    *Step `yaml:"ensure"`
+}
+
+// InParallel is configured either as a list of steps or as a mapping with
+// the steps under a steps key.
+type InParallel struct {
+	Steps []Step
+}
+
+func (p *InParallel) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	err := unmarshal(&p.Steps)
+	if err == nil {
+		return nil
+	}
+
+	var config struct {
+		Steps []Step `yaml:"steps"`
+	}
+	err = unmarshal(&config)
+	if err != nil {
+		return err
+	}
+
+	p.Steps = config.Steps
+	return nil
+}
+
+type TaskConfig struct {
+	Platform      string         `yaml:"platform"`
+	ImageResource *ImageResource `yaml:"image_resource"`
+}
+
+type ImageResource struct {
+	Type   string                 `yaml:"type"`
+	Source map[string]interface{} `yaml:"source"`
+}
+
+// ParsePipeline decodes the parts of a pipeline config, in yaml or json,
+// which are inspected by the resource.
+func ParsePipeline(b []byte) (Pipeline, error) {
+	var p Pipeline
+	err := yaml.Unmarshal(b, &p)
+	if err != nil {
+		return Pipeline{}, err
+	}
+
+	return p, nil
+}
+
+// Steps returns every step of the job, including the hooks of the job and
+// steps nested within other steps, in the order in which they appear.
+func (j Job) Steps() []Step {
+	var steps []Step
+	walk := func(s Step) {
+		steps = append(steps, s)
+	}
+
+	for _, s := range j.Plan {
+		s.Walk(walk)
+	}
+
+	for _, hook := range []*Step{j.OnSuccess, j.OnFailure, j.OnAbort, j.OnError, j.Ensure} {
+		if hook != nil {
+			hook.Walk(walk)
+		}
+	}
+
+	return steps
+}
+
+// Walk calls fn with the step and then each step nested within it.
+func (s Step) Walk(fn func(Step)) {
+	fn(s)
+
+	var nested []Step
+	nested = append(nested, s.Do...)
+	nested = append(nested, s.Aggregate...)
+	if s.InParallel != nil {
+		nested = append(nested, s.InParallel.Steps...)
+	}
+
+	for _, n := range nested {
+		n.Walk(fn)
+	}
+
+	for _, n := range []*Step{s.Try, s.OnSuccess, s.OnFailure, s.OnAbort, s.OnError, s.Ensure} {
+		if n != nil {
+			n.Walk(fn)
+		}
+	}
+}
+
+// ResourceName returns the name of the resource fetched or updated by a get
+// or put step, which defaults to the name of the step.
+func (s Step) ResourceName() string {
+	if s.Resource != "" {
+		return s.Resource
+	}
+	if s.Get != "" {
+		return s.Get
+	}
+	return s.Put
+}
+
+// ImageReference returns the image referenced by the source of an image
+// resource or resource type, as repository:tag or repository@digest. The
+// tag defaults to latest, as it does in Concourse, unless it is part of the
+// repository. An empty string is
+// returned if the source has no repository.
+func ImageReference(source map[string]interface{}) string {
+	repository, _ := source["repository"].(string)
+	if repository == "" {
+		return ""
+	}
+
+	if digest, ok := source["digest"].(string); ok && digest != "" {
+		return fmt.Sprintf("%s@%s", repository, digest)
+	}
+
+	tag := "latest"
+	if t, ok := source["tag"]; ok && t != nil {
+		tag = fmt.Sprint(t)
+	} else if strings.Contains(repository[strings.LastIndex(repository, "/")+1:], ":") {
+		// The tag is already part of the repository.
+		return repository
+	}
+
+	return fmt.Sprintf("%s:%s", repository, tag)
+}
@@ -0,0 +1,114 @@
+package config_test
+
+import (
+	"github.com/concourse/concourse-pipeline-resource/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParsePipeline", func() {
+	It("parses resources, jobs and groups", func() {
+		p, err := config.ParsePipeline([]byte(`resource_types:
+- name: cf-cli
+  type: docker-image
+  source: {repository: nulldriver/cf-cli-resource}
+resources:
+- name: repo
+  type: git
+jobs:
+- name: build
+  plan:
+  - get: repo
+    trigger: true
+    passed: [test]
+groups:
+- name: all
+  jobs: [build]
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(p.ResourceTypes).To(HaveLen(1))
+		Expect(p.ResourceTypes[0].Name).To(Equal("cf-cli"))
+		Expect(p.Resources).To(Equal([]config.Resource{{Name: "repo", Type: "git"}}))
+		Expect(p.Jobs).To(HaveLen(1))
+		Expect(p.Jobs[0].Plan[0].Get).To(Equal("repo"))
+		Expect(p.Jobs[0].Plan[0].Trigger).To(BeTrue())
+		Expect(p.Jobs[0].Plan[0].Passed).To(Equal([]string{"test"}))
+		Expect(p.Groups).To(Equal([]config.Group{{Name: "all", Jobs: []string{"build"}}}))
+	})
+
+	It("returns every step of a job, including nested steps and hooks", func() {
+		p, err := config.ParsePipeline([]byte(`jobs:
+- name: build
+  plan:
+  - in_parallel:
+    - get: a
+    - get: b
+  - in_parallel:
+      steps:
+      - get: c
+  - do:
+    - task: d
+      on_failure: {put: e}
+  - try: {task: f}
+  - aggregate:
+    - get: g
+  ensure: {put: h}
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		var names []string
+		for _, s := range p.Jobs[0].Steps() {
+			switch {
+			case s.Get != "":
+				names = append(names, s.Get)
+			case s.Put != "":
+				names = append(names, s.Put)
+			case s.Task != "":
+				names = append(names, s.Task)
+			}
+		}
+
+		Expect(names).To(Equal([]string{"a", "b", "c", "d", "e", "f", "g", "h"}))
+	})
+
+	It("parses json", func() {
+		p, err := config.ParsePipeline([]byte(`{"jobs": [{"name": "build", "plan": [{"put": "p", "resource": "repo"}]}]}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(p.Jobs[0].Plan[0].ResourceName()).To(Equal("repo"))
+	})
+
+	Context("when the config is invalid", func() {
+		It("returns an error", func() {
+			_, err := config.ParsePipeline([]byte("jobs: {{"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("ImageReference", func() {
+	It("defaults the tag to latest", func() {
+		Expect(config.ImageReference(map[string]interface{}{"repository": "busybox"})).To(Equal("busybox:latest"))
+	})
+
+	It("includes the tag", func() {
+		Expect(config.ImageReference(map[string]interface{}{"repository": "golang", "tag": 1.13})).To(Equal("golang:1.13"))
+	})
+
+	It("includes the digest in place of the tag", func() {
+		Expect(config.ImageReference(map[string]interface{}{
+			"repository": "golang",
+			"tag":        "1.13",
+			"digest":     "sha256:abc",
+		})).To(Equal("golang@sha256:abc"))
+	})
+
+	It("keeps a tag which is part of the repository", func() {
+		Expect(config.ImageReference(map[string]interface{}{"repository": "registry:5000/golang:1.13"})).To(Equal("registry:5000/golang:1.13"))
+	})
+
+	It("returns an empty string without a repository", func() {
+		Expect(config.ImageReference(map[string]interface{}{"uri": "git@example.com"})).To(BeEmpty())
+	})
+})
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SplitManifestFilename is the name of the manifest in the directory of a
+// pipeline config which has been split into a file per section.
+const SplitManifestFilename = "manifest.yml"
+
+// SplitManifest lists the top-level sections of a split pipeline config in
+// order, along with the files they were written to.
+type SplitManifest struct {
+	Sections []SplitSection `yaml:"sections"`
+}
+
+// SplitSection is a top-level section of a pipeline config, written either
+// to a single file or, for a list such as jobs, to a file per item.
+type SplitSection struct {
+	Key   string   `yaml:"key"`
+	File  string   `yaml:"file,omitempty"`
+	Files []string `yaml:"files,omitempty"`
+}
+
+// Merge reads the pipeline config which was split into dir, returning it as
+// yaml.
+func Merge(dir string) ([]byte, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, SplitManifestFilename))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest SplitManifest
+	err = yaml.Unmarshal(b, &manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.MapSlice
+	for _, section := range manifest.Sections {
+		var value interface{}
+
+		if section.File != "" {
+			value, err = readSplitFile(dir, section.File)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			items := []interface{}{}
+			for _, f := range section.Files {
+				item, err := readSplitFile(dir, f)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			value = items
+		}
+
+		doc = append(doc, yaml.MapItem{Key: section.Key, Value: value})
+	}
+
+	return yaml.Marshal(doc)
+}
+
+// readSplitFile reads a file of a split config, which must be within dir.
+func readSplitFile(dir string, path string) (interface{}, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(path))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("split config file '%s' must be within '%s'", path, dir)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, cleaned))
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	err = yaml.Unmarshal(b, &value)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
@@ -0,0 +1,90 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/concourse-pipeline-resource/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Merge", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = os.MkdirAll(filepath.Join(dir, "jobs"), 0755)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ioutil.WriteFile(filepath.Join(dir, "groups.yml"), []byte("- name: all\n  jobs: [a]\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "jobs", "a.json"), []byte(`{"name": "a", "plan": []}`), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	writeManifest := func(manifest string) {
+		Expect(ioutil.WriteFile(filepath.Join(dir, "manifest.yml"), []byte(manifest), 0644)).To(Succeed())
+	}
+
+	It("merges the sections in the order of the manifest", func() {
+		writeManifest(`sections:
+- key: jobs
+  files: [jobs/a.json]
+- key: groups
+  file: groups.yml
+- key: resources
+  files: []
+`)
+
+		b, err := config.Merge(dir)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(string(b)).To(Equal(`jobs:
+- name: a
+  plan: []
+groups:
+- jobs:
+  - a
+  name: all
+resources: []
+`))
+	})
+
+	Context("when a file is outside the directory", func() {
+		BeforeEach(func() {
+			writeManifest("sections:\n- key: groups\n  file: ../groups.yml\n")
+		})
+
+		It("returns an error", func() {
+			_, err := config.Merge(dir)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(ContainSubstring("must be within"))
+		})
+	})
+
+	Context("when a file does not exist", func() {
+		BeforeEach(func() {
+			writeManifest("sections:\n- key: jobs\n  files: [jobs/b.yml]\n")
+		})
+
+		It("returns an error", func() {
+			_, err := config.Merge(dir)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when there is no manifest", func() {
+		It("returns an error", func() {
+			_, err := config.Merge(dir)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,280 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	contextLines = 3
+
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+	colorReset = "\x1b[0m"
+)
+
+type operation int
+
+const (
+	opEqual operation = iota
+	opInsert
+	opDelete
+)
+
+type edit struct {
+	op      operation
+	text    string
+	oldLine int
+	newLine int
+}
+
+// Unified returns a unified diff between old and new, or an empty string if
+// they do not differ.
+func Unified(oldName string, newName string, old []byte, new []byte) string {
+	edits := editScript(splitLines(old), splitLines(new))
+
+	var changed []int
+	for i, e := range edits {
+		if e.op != opEqual {
+			changed = append(changed, i)
+		}
+	}
+
+	if len(changed) == 0 {
+		return ""
+	}
+
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "--- %s\n", oldName)
+	fmt.Fprintf(buf, "+++ %s\n", newName)
+
+	for start := 0; start < len(changed); {
+		end := start
+		for end+1 < len(changed) && changed[end+1]-changed[end] <= 2*contextLines {
+			end++
+		}
+
+		first := changed[start] - contextLines
+		if first < 0 {
+			first = 0
+		}
+
+		last := changed[end] + contextLines
+		if last > len(edits)-1 {
+			last = len(edits) - 1
+		}
+
+		writeHunk(buf, edits[first:last+1])
+		start = end + 1
+	}
+
+	return buf.String()
+}
+
+// Colorize adds ANSI colors to the added, removed and hunk header lines of a
+// unified diff.
+func Colorize(unified string) string {
+	if unified == "" {
+		return ""
+	}
+
+	lines := strings.SplitAfter(unified, "\n")
+	for i, l := range lines {
+		trimmed := strings.TrimSuffix(l, "\n")
+		suffix := l[len(trimmed):]
+
+		switch {
+		case strings.HasPrefix(l, "+++"), strings.HasPrefix(l, "---"):
+		case strings.HasPrefix(l, "+"):
+			lines[i] = colorGreen + trimmed + colorReset + suffix
+		case strings.HasPrefix(l, "-"):
+			lines[i] = colorRed + trimmed + colorReset + suffix
+		case strings.HasPrefix(l, "@@"):
+			lines[i] = colorCyan + trimmed + colorReset + suffix
+		}
+	}
+
+	return strings.Join(lines, "")
+}
+
+func writeHunk(buf *bytes.Buffer, edits []edit) {
+	var oldStart, newStart, oldCount, newCount int
+	oldStart = -1
+	newStart = -1
+
+	for _, e := range edits {
+		if e.op != opInsert {
+			if oldStart == -1 {
+				oldStart = e.oldLine
+			}
+			oldCount++
+		}
+		if e.op != opDelete {
+			if newStart == -1 {
+				newStart = e.newLine
+			}
+			newCount++
+		}
+	}
+
+	// By convention an empty range starts at the line before it.
+	if oldStart == -1 {
+		oldStart = edits[0].oldLine - 1
+	}
+	if newStart == -1 {
+		newStart = edits[0].newLine - 1
+	}
+
+	fmt.Fprintf(buf, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+
+	for _, e := range edits {
+		switch e.op {
+		case opEqual:
+			fmt.Fprintf(buf, " %s\n", e.text)
+		case opInsert:
+			fmt.Fprintf(buf, "+%s\n", e.text)
+		case opDelete:
+			fmt.Fprintf(buf, "-%s\n", e.text)
+		}
+	}
+}
+
+func hunkRange(start int, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(b []byte) []string {
+	s := strings.TrimSuffix(string(b), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// editScript returns the shortest edit script turning a into b, using the
+// Myers diff algorithm. Line numbers in the returned edits are 1-based; for
+// inserted and deleted lines the line number on the other side is that of the
+// line which follows the edit.
+func editScript(a []string, b []string) []edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var edits []edit
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, edit{op: opEqual, text: a[i], oldLine: i + 1, newLine: i + 1})
+	}
+
+	for _, e := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		e.oldLine += prefix
+		e.newLine += prefix
+		edits = append(edits, e)
+	}
+
+	for i := suffix; i > 0; i-- {
+		edits = append(edits, edit{
+			op:      opEqual,
+			text:    a[len(a)-i],
+			oldLine: len(a) - i + 1,
+			newLine: len(b) - i + 1,
+		})
+	}
+
+	return edits
+}
+
+func myers(a []string, b []string) []edit {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+
+	// v[k+offset] holds the furthest x reached on diagonal k. A copy of the
+	// relevant part of v is kept for every d so the path can be retraced.
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= max && !found; d++ {
+		snapshot := make([]int, 2*d+3)
+		copy(snapshot, v[offset-d-1:offset+d+2])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	var reversed []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		snapshot := trace[d]
+		at := func(k int) int { return snapshot[k+d+1] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, edit{op: opEqual, text: a[x-1], oldLine: x, newLine: y})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, edit{op: opInsert, text: b[y-1], oldLine: x + 1, newLine: y})
+			} else {
+				reversed = append(reversed, edit{op: opDelete, text: a[x-1], oldLine: x, newLine: y + 1})
+			}
+		}
+
+		x, y = prevX, prevY
+	}
+
+	edits := make([]edit, len(reversed))
+	for i, e := range reversed {
+		edits[len(reversed)-1-i] = e
+	}
+
+	return edits
+}
@@ -0,0 +1,112 @@
+package diff_test
+
+import (
+	"github.com/concourse/concourse-pipeline-resource/diff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Unified", func() {
+	var (
+		old string
+		new string
+	)
+
+	BeforeEach(func() {
+		old = `jobs:
+- name: job-1
+  plan:
+  - get: repo
+  - task: unit
+- name: job-2
+  plan:
+  - get: repo
+`
+		new = `jobs:
+- name: job-1
+  plan:
+  - get: repo
+  - task: integration
+- name: job-2
+  plan:
+  - get: repo
+`
+	})
+
+	It("returns a unified diff of the changed lines", func() {
+		unified := diff.Unified("old", "new", []byte(old), []byte(new))
+
+		Expect(unified).To(Equal(`--- old
++++ new
+@@ -2,7 +2,7 @@
+ - name: job-1
+   plan:
+   - get: repo
+-  - task: unit
++  - task: integration
+ - name: job-2
+   plan:
+   - get: repo
+`))
+	})
+
+	Context("when the configs are identical", func() {
+		It("returns an empty string", func() {
+			Expect(diff.Unified("old", "new", []byte(old), []byte(old))).To(BeEmpty())
+		})
+	})
+
+	Context("when the old config is empty", func() {
+		It("returns every line as added", func() {
+			unified := diff.Unified("old", "new", nil, []byte("a: b\nc: d\n"))
+
+			Expect(unified).To(Equal(`--- old
++++ new
+@@ -0,0 +1,2 @@
++a: b
++c: d
+`))
+		})
+	})
+
+	Context("when changes are far apart", func() {
+		BeforeEach(func() {
+			old = "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+			new = "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n12\n"
+		})
+
+		It("returns separate hunks", func() {
+			unified := diff.Unified("old", "new", []byte(old), []byte(new))
+
+			Expect(unified).To(Equal(`--- old
++++ new
+@@ -1,3 +1,4 @@
++0
+ 1
+ 2
+ 3
+@@ -8,5 +9,4 @@
+ 8
+ 9
+ 10
+-11
+ 12
+`))
+		})
+	})
+})
+
+var _ = Describe("Colorize", func() {
+	It("colors added, removed and hunk header lines", func() {
+		colorized := diff.Colorize("--- old\n+++ new\n@@ -1 +1 @@\n-a\n+b\n c\n")
+
+		Expect(colorized).To(Equal(
+			"--- old\n" +
+				"+++ new\n" +
+				"\x1b[36m@@ -1 +1 @@\x1b[0m\n" +
+				"\x1b[31m-a\x1b[0m\n" +
+				"\x1b[32m+b\x1b[0m\n" +
+				" c\n",
+		))
+	})
+})
@@ -0,0 +1,108 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Changes lists the names of the items in a section of a pipeline config
+// which were added, removed or changed.
+type Changes struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+func (c Changes) Count() int {
+	return len(c.Added) + len(c.Removed) + len(c.Changed)
+}
+
+type Summary struct {
+	Jobs      Changes
+	Resources Changes
+}
+
+type sections struct {
+	Jobs      []map[string]interface{} `yaml:"jobs"`
+	Resources []map[string]interface{} `yaml:"resources"`
+}
+
+// Summarize compares the jobs and resources of two pipeline configs by name.
+// An empty config is treated as a pipeline with no jobs or resources.
+func Summarize(old []byte, new []byte) (Summary, error) {
+	var oldSections, newSections sections
+
+	err := yaml.Unmarshal(old, &oldSections)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	err = yaml.Unmarshal(new, &newSections)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	jobs, err := compare("jobs", oldSections.Jobs, newSections.Jobs)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	resources, err := compare("resources", oldSections.Resources, newSections.Resources)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	return Summary{
+		Jobs:      jobs,
+		Resources: resources,
+	}, nil
+}
+
+func compare(section string, old []map[string]interface{}, new []map[string]interface{}) (Changes, error) {
+	oldByName, err := byName(section, old)
+	if err != nil {
+		return Changes{}, err
+	}
+
+	var changes Changes
+	seen := make(map[string]bool)
+
+	for _, item := range new {
+		name, ok := item["name"].(string)
+		if !ok {
+			return Changes{}, fmt.Errorf("%s must have a name", section)
+		}
+		seen[name] = true
+
+		oldItem, found := oldByName[name]
+		switch {
+		case !found:
+			changes.Added = append(changes.Added, name)
+		case !reflect.DeepEqual(oldItem, item):
+			changes.Changed = append(changes.Changed, name)
+		}
+	}
+
+	for _, item := range old {
+		name := item["name"].(string)
+		if !seen[name] {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+
+	return changes, nil
+}
+
+func byName(section string, items []map[string]interface{}) (map[string]map[string]interface{}, error) {
+	named := make(map[string]map[string]interface{})
+	for _, item := range items {
+		name, ok := item["name"].(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must have a name", section)
+		}
+		named[name] = item
+	}
+	return named, nil
+}
@@ -0,0 +1,84 @@
+package diff_test
+
+import (
+	"github.com/concourse/concourse-pipeline-resource/diff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Summarize", func() {
+	var (
+		old string
+		new string
+	)
+
+	BeforeEach(func() {
+		old = `resources:
+- name: repo
+  type: git
+  source: {uri: old-uri}
+- name: timer
+  type: time
+jobs:
+- name: unchanged
+  plan: [{get: repo}]
+- name: changed
+  plan: [{get: repo}]
+- name: removed
+  plan: [{get: timer}]
+`
+		new = `resources:
+- name: repo
+  type: git
+  source: {uri: new-uri}
+- name: timer
+  type: time
+jobs:
+- name: unchanged
+  plan: [{get: repo}]
+- name: changed
+  plan: [{get: repo, trigger: true}]
+- name: added
+  plan: [{get: timer}]
+`
+	})
+
+	It("returns added, removed and changed jobs and resources", func() {
+		summary, err := diff.Summarize([]byte(old), []byte(new))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(summary.Jobs.Added).To(Equal([]string{"added"}))
+		Expect(summary.Jobs.Removed).To(Equal([]string{"removed"}))
+		Expect(summary.Jobs.Changed).To(Equal([]string{"changed"}))
+		Expect(summary.Jobs.Count()).To(Equal(3))
+
+		Expect(summary.Resources.Added).To(BeEmpty())
+		Expect(summary.Resources.Removed).To(BeEmpty())
+		Expect(summary.Resources.Changed).To(Equal([]string{"repo"}))
+		Expect(summary.Resources.Count()).To(Equal(1))
+	})
+
+	Context("when the old config is empty", func() {
+		It("returns everything as added", func() {
+			summary, err := diff.Summarize(nil, []byte(new))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(summary.Jobs.Added).To(Equal([]string{"unchanged", "changed", "added"}))
+			Expect(summary.Resources.Added).To(Equal([]string{"repo", "timer"}))
+		})
+	})
+
+	Context("when a config fails to parse", func() {
+		It("returns an error", func() {
+			_, err := diff.Summarize([]byte("{{"), []byte(new))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when a job has no name", func() {
+		It("returns an error", func() {
+			_, err := diff.Summarize(nil, []byte("jobs:\n- plan: []\n"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,195 @@
+package filereader_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/concourse-pipeline-resource/concourse"
+	"github.com/concourse/concourse-pipeline-resource/filereader"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PipelinesFromDir", func() {
+	var (
+		sourcesDir   string
+		pipelinesDir string
+	)
+
+	writeFile := func(path string, contents string) {
+		fullPath := filepath.Join(sourcesDir, path)
+		Expect(os.MkdirAll(filepath.Dir(fullPath), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(fullPath, []byte(contents), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		sourcesDir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		pipelinesDir = "repo/pipelines"
+
+		writeFile("repo/pipelines/main/build.yml", "jobs: []\n")
+		writeFile("repo/pipelines/main/build.vars.yml", "foo: bar\n")
+		writeFile("repo/pipelines/main/deploy.yml", "jobs: []\n")
+		writeFile("repo/pipelines/other/release.yml", "jobs: []\n")
+		writeFile("repo/pipelines/other/README.md", "not a pipeline\n")
+		writeFile("repo/pipelines/other/.hidden.yml", "jobs: []\n")
+		writeFile("repo/pipelines/_shared/vars.yml", "foo: bar\n")
+		writeFile("repo/pipelines/README.md", "not a team\n")
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(sourcesDir)).To(Succeed())
+	})
+
+	It("returns a pipeline for each config in each team directory", func() {
+		pipelines, err := filereader.PipelinesFromDir(pipelinesDir, sourcesDir)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(pipelines).To(Equal([]concourse.Pipeline{
+			{
+				Name:       "build",
+				TeamName:   "main",
+				ConfigFile: "repo/pipelines/main/build.yml",
+				VarsFiles:  []string{"repo/pipelines/main/build.vars.yml"},
+			},
+			{
+				Name:       "deploy",
+				TeamName:   "main",
+				ConfigFile: "repo/pipelines/main/deploy.yml",
+			},
+			{
+				Name:       "release",
+				TeamName:   "other",
+				ConfigFile: "repo/pipelines/other/release.yml",
+			},
+		}))
+	})
+
+	Context("when there are defaults", func() {
+		BeforeEach(func() {
+			writeFile("repo/pipelines/_defaults.yml", `
+unpaused: true
+vars_files: [repo/pipelines/_shared/vars.yml]
+vars: {env: dev, region: eu}
+`)
+			writeFile("repo/pipelines/other/_defaults.yml", `
+exposed: true
+vars: {env: prod}
+`)
+		})
+
+		It("applies them to every pipeline, overridden by the team defaults", func() {
+			pipelines, err := filereader.PipelinesFromDir(pipelinesDir, sourcesDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(pipelines).To(HaveLen(3))
+
+			Expect(pipelines[0].Unpaused).To(BeTrue())
+			Expect(pipelines[0].Exposed).To(BeFalse())
+			Expect(pipelines[0].VarsFiles).To(Equal([]string{
+				"repo/pipelines/_shared/vars.yml",
+				"repo/pipelines/main/build.vars.yml",
+			}))
+			Expect(pipelines[0].Vars).To(Equal(map[string]interface{}{"env": "dev", "region": "eu"}))
+
+			Expect(pipelines[1].VarsFiles).To(Equal([]string{"repo/pipelines/_shared/vars.yml"}))
+
+			Expect(pipelines[2].Unpaused).To(BeTrue())
+			Expect(pipelines[2].Exposed).To(BeTrue())
+			Expect(pipelines[2].Vars).To(Equal(map[string]interface{}{"env": "prod", "region": "eu"}))
+		})
+
+		Context("when the team defaults append to a list and merge a map", func() {
+			BeforeEach(func() {
+				writeFile("repo/pipelines/_defaults.yml", `
+vars_files: [repo/pipelines/_shared/vars.yml]
+vars: {slack: {channel: "#ci", icon: ":concourse:"}}
+`)
+				writeFile("repo/pipelines/main/_defaults.yml", `
+vars_files+: [repo/pipelines/main/vars.yml]
+vars: {slack: {channel: "#main"}}
+`)
+			})
+
+			It("merges them as the defaults of a pipelines file", func() {
+				pipelines, err := filereader.PipelinesFromDir(pipelinesDir, sourcesDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(pipelines[0].VarsFiles).To(Equal([]string{
+					"repo/pipelines/_shared/vars.yml",
+					"repo/pipelines/main/vars.yml",
+					"repo/pipelines/main/build.vars.yml",
+				}))
+				Expect(pipelines[0].Vars).To(Equal(map[string]interface{}{
+					"slack": map[interface{}]interface{}{"channel": "#main", "icon": ":concourse:"},
+				}))
+
+				Expect(pipelines[2].VarsFiles).To(Equal([]string{"repo/pipelines/_shared/vars.yml"}))
+				Expect(pipelines[2].Vars).To(Equal(map[string]interface{}{
+					"env":   "prod",
+					"slack": map[interface{}]interface{}{"channel": "#ci", "icon": ":concourse:"},
+				}))
+			})
+		})
+
+		Context("when the team defaults append to a value which is not a list", func() {
+			BeforeEach(func() {
+				writeFile("repo/pipelines/main/_defaults.yml", "vars+: [foo]\n")
+			})
+
+			It("returns an error naming the team directory", func() {
+				_, err := filereader.PipelinesFromDir(pipelinesDir, sourcesDir)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(Equal(
+					"repo/pipelines/main: team_defaults.main: vars must be a list to be appended to with vars+",
+				))
+			})
+		})
+
+		Context("when the defaults name a pipeline", func() {
+			BeforeEach(func() {
+				writeFile("repo/pipelines/main/_defaults.yml", "name: some-pipeline\n")
+			})
+
+			It("returns an error", func() {
+				_, err := filereader.PipelinesFromDir(pipelinesDir, sourcesDir)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(MatchRegexp("name cannot be provided in .*_defaults.yml"))
+			})
+		})
+
+		Context("when the defaults fail to parse", func() {
+			BeforeEach(func() {
+				writeFile("repo/pipelines/_defaults.yml", "{{")
+			})
+
+			It("returns an error", func() {
+				_, err := filereader.PipelinesFromDir(pipelinesDir, sourcesDir)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Context("when the directory does not exist", func() {
+		BeforeEach(func() {
+			pipelinesDir = "missing"
+		})
+
+		It("returns an error", func() {
+			_, err := filereader.PipelinesFromDir(pipelinesDir, sourcesDir)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when sourcesDir is empty", func() {
+		It("returns an error", func() {
+			_, err := filereader.PipelinesFromDir(pipelinesDir, "")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
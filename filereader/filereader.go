@@ -0,0 +1,240 @@
+package filereader
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/concourse/concourse-pipeline-resource/concourse"
+	"gopkg.in/yaml.v2"
+)
+
+func PipelinesFromFile(pipelinesFilename string, sourcesDir string) ([]concourse.Pipeline, error) {
+	if pipelinesFilename != "" {
+		if sourcesDir == "" {
+			return nil, fmt.Errorf("sourcesDir must be non-empty")
+		}
+
+		b, err := ioutil.ReadFile(filepath.Join(sourcesDir, pipelinesFilename))
+		if err != nil {
+			return nil, err
+		}
+
+		var fileContents struct {
+			concourse.PipelineDefaults `yaml:",inline"`
+			Pipelines                  []map[string]interface{} `yaml:"pipelines"`
+		}
+		err = yaml.Unmarshal(b, &fileContents)
+		if err != nil {
+			return nil, err
+		}
+
+		entries, err := fileContents.PipelineDefaults.Apply(fileContents.Pipelines)
+		if err != nil {
+			return nil, err
+		}
+
+		entries, err = concourse.ExpandMatrix(entries)
+		if err != nil {
+			return nil, err
+		}
+
+		return decodePipelines(entries)
+	}
+
+	return []concourse.Pipeline{}, nil
+}
+
+// PipelinesFromFiles returns the pipelines in each of the given files, in
+// order. Files may be given as glob patterns relative to sourcesDir, the
+// matches of which are read in lexical order. A pipeline for the same team
+// and name cannot be declared in more than one file.
+func PipelinesFromFiles(patterns []string, sourcesDir string) ([]concourse.Pipeline, error) {
+	if sourcesDir == "" {
+		return nil, fmt.Errorf("sourcesDir must be non-empty")
+	}
+
+	pipelines := []concourse.Pipeline{}
+	declaredIn := map[string]string{}
+	read := map[string]bool{}
+
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(sourcesDir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pipelines_file pattern '%s': %s", pattern, err)
+		}
+
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("pipelines_file '%s' did not match any files", pattern)
+		}
+
+		for _, match := range matches {
+			filename, err := filepath.Rel(sourcesDir, match)
+			if err != nil {
+				return nil, err
+			}
+
+			if read[filename] {
+				continue
+			}
+			read[filename] = true
+
+			filePipelines, err := PipelinesFromFile(filename, sourcesDir)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", filename, err)
+			}
+
+			for _, p := range filePipelines {
+				key := p.TeamName + "/" + p.Name
+				if previous, found := declaredIn[key]; found && previous != filename {
+					return nil, fmt.Errorf("pipeline '%s' is declared in both '%s' and '%s'", key, previous, filename)
+				}
+				declaredIn[key] = filename
+
+				pipelines = append(pipelines, p)
+			}
+		}
+	}
+
+	return pipelines, nil
+}
+
+const (
+	defaultsFilename  = "_defaults.yml"
+	varsFileExtension = ".vars.yml"
+)
+
+// PipelinesFromDir returns the pipelines in pipelinesDir, which is relative
+// to sourcesDir, following the convention <team>/<pipeline>.yml. A
+// <pipeline>.vars.yml alongside a pipeline is appended to its vars files.
+// Settings common to every pipeline, such as unpaused or vars, can be given
+// in a _defaults.yml in pipelinesDir, and overridden for a team in a
+// _defaults.yml in the team's directory, which are merged as the defaults
+// and team_defaults of a pipelines file. Files and directories starting with
+// _ or . are otherwise ignored. Paths in defaults are relative to sourcesDir.
+func PipelinesFromDir(pipelinesDir string, sourcesDir string) ([]concourse.Pipeline, error) {
+	if sourcesDir == "" {
+		return nil, fmt.Errorf("sourcesDir must be non-empty")
+	}
+
+	defaults, err := readDefaults(filepath.Join(sourcesDir, pipelinesDir, defaultsFilename))
+	if err != nil {
+		return nil, err
+	}
+
+	teamDirs, err := ioutil.ReadDir(filepath.Join(sourcesDir, pipelinesDir))
+	if err != nil {
+		return nil, err
+	}
+
+	pipelines := []concourse.Pipeline{}
+	for _, teamDir := range teamDirs {
+		if !teamDir.IsDir() || ignored(teamDir.Name()) {
+			continue
+		}
+
+		teamName := teamDir.Name()
+		teamPath := filepath.Join(pipelinesDir, teamName)
+
+		teamDefaults, err := readDefaults(filepath.Join(sourcesDir, teamPath, defaultsFilename))
+		if err != nil {
+			return nil, err
+		}
+
+		files, err := ioutil.ReadDir(filepath.Join(sourcesDir, teamPath))
+		if err != nil {
+			return nil, err
+		}
+
+		var entries []map[string]interface{}
+		for _, f := range files {
+			name := f.Name()
+			if f.IsDir() || ignored(name) || filepath.Ext(name) != ".yml" || strings.HasSuffix(name, varsFileExtension) {
+				continue
+			}
+
+			pipelineName := strings.TrimSuffix(name, ".yml")
+			entry := map[string]interface{}{
+				"name":        pipelineName,
+				"team":        teamName,
+				"config_file": filepath.Join(teamPath, name),
+			}
+
+			varsFile := filepath.Join(teamPath, pipelineName+varsFileExtension)
+			_, err := os.Stat(filepath.Join(sourcesDir, varsFile))
+			if err == nil {
+				entry["vars_files"+concourse.AppendSuffix] = []interface{}{varsFile}
+			} else if !os.IsNotExist(err) {
+				return nil, err
+			}
+
+			entries = append(entries, entry)
+		}
+
+		entries, err = concourse.PipelineDefaults{
+			Defaults:     defaults,
+			TeamDefaults: map[string]map[string]interface{}{teamName: teamDefaults},
+		}.Apply(entries)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", teamPath, err)
+		}
+
+		teamPipelines, err := decodePipelines(entries)
+		if err != nil {
+			return nil, err
+		}
+
+		pipelines = append(pipelines, teamPipelines...)
+	}
+
+	return pipelines, nil
+}
+
+// readDefaults reads the defaults file, if it exists. The name, team and
+// config of a pipeline cannot have defaults.
+func readDefaults(defaultsFilepath string) (map[string]interface{}, error) {
+	b, err := ioutil.ReadFile(defaultsFilepath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var defaults map[string]interface{}
+	err = yaml.Unmarshal(b, &defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range []string{"name", "team", "config_file", "config_dir", "renamed_from"} {
+		if _, found := defaults[key]; found {
+			return nil, fmt.Errorf("%s cannot be provided in %s", key, defaultsFilepath)
+		}
+	}
+
+	return defaults, nil
+}
+
+// decodePipelines decodes pipeline entries to which defaults have been
+// applied.
+func decodePipelines(entries []map[string]interface{}) ([]concourse.Pipeline, error) {
+	b, err := yaml.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	var pipelines []concourse.Pipeline
+	err = yaml.Unmarshal(b, &pipelines)
+	if err != nil {
+		return nil, err
+	}
+
+	return pipelines, nil
+}
+
+func ignored(name string) bool {
+	return strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".")
+}
@@ -11,7 +11,7 @@ const (
 	apiPrefix = "/api/v1"
 )
 
-func TestOutFilereader(t *testing.T) {
+func TestFilereader(t *testing.T) {
 	RegisterFailHandler(Fail)
-	RunSpecs(t, "OutFilereader Suite")
+	RunSpecs(t, "Filereader Suite")
 }
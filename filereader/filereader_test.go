@@ -5,8 +5,8 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/concourse/concourse-pipeline-resource/cmd/out/filereader"
 	"github.com/concourse/concourse-pipeline-resource/concourse"
+	"github.com/concourse/concourse-pipeline-resource/filereader"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"gopkg.in/yaml.v2"
@@ -72,6 +72,118 @@ var _ = Describe("Filereader", func() {
 		Expect(returnedPipelines).To(Equal(pipelines))
 	})
 
+	Context("when the pipelines file has defaults", func() {
+		BeforeEach(func() {
+			pipelinesFileContentsBytes := []byte(`
+defaults:
+  team: main
+  unpaused: true
+  vars_files: [common.yml]
+  vars:
+    slack: {channel: "#ci", icon: ":concourse:"}
+team_defaults:
+  other:
+    exposed: true
+    vars_files+: [other.yml]
+pipelines:
+- name: name-1
+  config_file: pipeline_1.yml
+  vars:
+    slack: {channel: "#name-1"}
+- name: name-2
+  team: other
+  config_file: pipeline_2.yml
+  vars_files+: [vars_2.yml]
+`)
+
+			err := ioutil.WriteFile(
+				filepath.Join(sourcesDir, pipelinesFilename),
+				pipelinesFileContentsBytes,
+				os.ModePerm,
+			)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("merges each pipeline over the defaults for its team", func() {
+			returnedPipelines, err := filereader.PipelinesFromFile(pipelinesFilename, sourcesDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(returnedPipelines).To(Equal([]concourse.Pipeline{
+				{
+					Name:       "name-1",
+					TeamName:   "main",
+					ConfigFile: "pipeline_1.yml",
+					VarsFiles:  []string{"common.yml"},
+					Vars: map[string]interface{}{
+						"slack": map[interface{}]interface{}{
+							"channel": "#name-1",
+							"icon":    ":concourse:",
+						},
+					},
+					Unpaused: true,
+				},
+				{
+					Name:       "name-2",
+					TeamName:   "other",
+					ConfigFile: "pipeline_2.yml",
+					VarsFiles:  []string{"common.yml", "other.yml", "vars_2.yml"},
+					Vars: map[string]interface{}{
+						"slack": map[interface{}]interface{}{
+							"channel": "#ci",
+							"icon":    ":concourse:",
+						},
+					},
+					Unpaused: true,
+					Exposed:  true,
+				},
+			}))
+		})
+	})
+
+	Context("when a pipeline in the pipelines file has a matrix", func() {
+		BeforeEach(func() {
+			pipelinesFileContentsBytes := []byte(`
+defaults:
+  team: main
+  vars_files: [vars/((env)).yml]
+pipelines:
+- name: deploy-((env))
+  config_file: deploy.yml
+  matrix:
+    env: [dev, prod]
+`)
+
+			err := ioutil.WriteFile(
+				filepath.Join(sourcesDir, pipelinesFilename),
+				pipelinesFileContentsBytes,
+				os.ModePerm,
+			)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns a pipeline for each value with its own vars", func() {
+			returnedPipelines, err := filereader.PipelinesFromFile(pipelinesFilename, sourcesDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(returnedPipelines).To(Equal([]concourse.Pipeline{
+				{
+					Name:       "deploy-dev",
+					TeamName:   "main",
+					ConfigFile: "deploy.yml",
+					VarsFiles:  []string{"vars/dev.yml"},
+					Vars:       map[string]interface{}{"env": "dev"},
+				},
+				{
+					Name:       "deploy-prod",
+					TeamName:   "main",
+					ConfigFile: "deploy.yml",
+					VarsFiles:  []string{"vars/prod.yml"},
+					Vars:       map[string]interface{}{"env": "prod"},
+				},
+			}))
+		})
+	})
+
 	Context("when sourcesDir is empty", func() {
 		BeforeEach(func() {
 			sourcesDir = ""
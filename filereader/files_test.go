@@ -0,0 +1,149 @@
+package filereader_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/concourse-pipeline-resource/concourse"
+	"github.com/concourse/concourse-pipeline-resource/filereader"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PipelinesFromFiles", func() {
+	var sourcesDir string
+
+	writeFile := func(path string, contents string) {
+		fullPath := filepath.Join(sourcesDir, path)
+		Expect(os.MkdirAll(filepath.Dir(fullPath), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(fullPath, []byte(contents), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		sourcesDir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		writeFile("repo/common.yml", `
+pipelines:
+- name: common
+  team: main
+  config_file: repo/common/pipeline.yml
+`)
+		writeFile("repo/products/web/pipelines.yml", `
+pipelines:
+- name: web
+  team: main
+  config_file: repo/products/web/pipeline.yml
+`)
+		writeFile("repo/products/api/pipelines.yml", `
+pipelines:
+- name: api
+  team: main
+  config_file: repo/products/api/pipeline.yml
+- name: api
+  team: other
+  config_file: repo/products/api/pipeline.yml
+`)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(sourcesDir)).To(Succeed())
+	})
+
+	names := func(pipelines []concourse.Pipeline) []string {
+		n := []string{}
+		for _, p := range pipelines {
+			n = append(n, p.TeamName+"/"+p.Name)
+		}
+		return n
+	}
+
+	It("merges the pipelines of each file and glob match in order", func() {
+		pipelines, err := filereader.PipelinesFromFiles(
+			[]string{"repo/common.yml", "repo/products/*/pipelines.yml"},
+			sourcesDir,
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(names(pipelines)).To(Equal([]string{"main/common", "main/api", "other/api", "main/web"}))
+		Expect(pipelines[0].ConfigFile).To(Equal("repo/common/pipeline.yml"))
+	})
+
+	It("reads a file matched by more than one pattern once", func() {
+		pipelines, err := filereader.PipelinesFromFiles(
+			[]string{"repo/products/web/pipelines.yml", "repo/products/*/pipelines.yml"},
+			sourcesDir,
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(names(pipelines)).To(Equal([]string{"main/web", "main/api", "other/api"}))
+	})
+
+	It("reads a file whose path contains escaped glob characters", func() {
+		writeFile("repo/[legacy].yml", `
+pipelines:
+- name: legacy
+  team: main
+  config_file: repo/legacy/pipeline.yml
+`)
+
+		pipelines, err := filereader.PipelinesFromFiles([]string{`repo/\[legacy\].yml`}, sourcesDir)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(names(pipelines)).To(Equal([]string{"main/legacy"}))
+	})
+
+	Context("when a pipeline is declared in more than one file", func() {
+		BeforeEach(func() {
+			writeFile("repo/products/web/more.yml", `
+pipelines:
+- name: web
+  team: main
+  config_file: repo/products/web/other.yml
+`)
+		})
+
+		It("returns an error naming both files", func() {
+			_, err := filereader.PipelinesFromFiles(
+				[]string{"repo/products/*/*.yml"},
+				sourcesDir,
+			)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(Equal(
+				"pipeline 'main/web' is declared in both 'repo/products/web/more.yml' and 'repo/products/web/pipelines.yml'",
+			))
+		})
+	})
+
+	Context("when a pattern does not match any files", func() {
+		It("returns an error", func() {
+			_, err := filereader.PipelinesFromFiles([]string{"repo/missing/*.yml"}, sourcesDir)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(ContainSubstring("repo/missing/*.yml"))
+		})
+	})
+
+	Context("when a file fails to parse", func() {
+		BeforeEach(func() {
+			writeFile("repo/broken.yml", "{{")
+		})
+
+		It("returns an error naming the file", func() {
+			_, err := filereader.PipelinesFromFiles([]string{"repo/*.yml"}, sourcesDir)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(HavePrefix("repo/broken.yml: "))
+		})
+	})
+
+	Context("when sourcesDir is empty", func() {
+		It("returns an error", func() {
+			_, err := filereader.PipelinesFromFiles([]string{"repo/common.yml"}, "")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
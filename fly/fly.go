@@ -2,14 +2,29 @@ package fly
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"crypto/tls"
 	"net/http"
 
 	"github.com/concourse/concourse-pipeline-resource/logger"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	infoPath = "/api/v1/info"
+
+	// Sessions whose token expires within this margin are logged in again,
+	// rather than risking the token expiring mid-command.
+	expiryMargin = time.Minute
 )
 
 //go:generate counterfeiter . Command
@@ -17,17 +32,37 @@ import (
 type Command interface {
 	Login(url string, teamName string, username string, password string, insecure bool) ([]byte, error)
 	Pipelines() ([]string, error)
-	GetPipeline(pipelineName string) ([]byte, error)
-	SetPipeline(pipelineName string, configFilepath string, varsFilepaths []string, vars map[string]interface{}) ([]byte, error)
+	PipelineStatuses() ([]PipelineStatus, error)
+	GetPipeline(pipelineName string, asJSON bool) ([]byte, error)
+	ValidatePipeline(configFilepath string, varsFilepaths []string, vars map[string]interface{}, strict bool) ([]byte, error)
+	RenderPipeline(configFilepath string, varsFilepaths []string, vars map[string]interface{}) ([]byte, error)
+	SetPipeline(pipelineName string, configFilepath string, varsFilepaths []string, vars map[string]interface{}, checkCreds bool) ([]byte, error)
 	DestroyPipeline(pipelineName string) ([]byte, error)
+	RenamePipeline(oldName string, newName string) ([]byte, error)
 	UnpausePipeline(pipelineName string) ([]byte, error)
 	ExposePipeline(pipelineName string) ([]byte, error)
 }
 
+type PipelineStatus struct {
+	Name   string `json:"name"`
+	Paused bool   `json:"paused"`
+	Public bool   `json:"public"`
+}
+
 type command struct {
 	target        string
 	logger        logger.Logger
 	flyBinaryPath string
+
+	// Each team is logged in to its own fly target, so sessions can be
+	// reused when switching between teams.
+	team     string
+	sessions map[string]session
+	synced   bool
+}
+
+type session struct {
+	expiry time.Time
 }
 
 func NewCommand(target string, logger logger.Logger, flyBinaryPath string) Command {
@@ -35,16 +70,23 @@ func NewCommand(target string, logger logger.Logger, flyBinaryPath string) Comma
 		target:        target,
 		logger:        logger,
 		flyBinaryPath: flyBinaryPath,
+		sessions:      make(map[string]session),
 	}
 }
 
-func (f command) Login(
+func (f *command) Login(
 	url string,
 	teamName string,
 	username string,
 	password string,
 	insecure bool,
 ) ([]byte, error) {
+	if s, ok := f.sessions[teamName]; ok && s.valid() {
+		f.logger.Debugf("Reusing session for team: %s\n", teamName)
+		f.team = teamName
+		return nil, nil
+	}
+
 	args := []string{
 		"login",
 		"-c", url,
@@ -64,54 +106,131 @@ func (f command) Login(
 		http.DefaultClient.Transport = tr
 	}
 
+	f.team = teamName
 	loginOut, err := f.run(args...)
 	if err != nil {
 		return nil, err
 	}
 
+	f.sessions[teamName] = session{
+		expiry: f.tokenExpiry(),
+	}
+
+	if f.synced {
+		return loginOut, nil
+	}
+
+	if f.versionsMatch(url) {
+		f.logger.Debugf("fly and ATC versions match; not syncing\n")
+		f.synced = true
+		return loginOut, nil
+	}
+
 	syncOut, err := f.run("sync")
 	if err != nil {
 		return nil, err
 	}
+	f.synced = true
 
 	return append(loginOut, syncOut...), nil
 }
 
-func (f command) Pipelines() ([]string, error) {
-	psOut, err := f.run("pipelines", "--json")
+func (f *command) Pipelines() ([]string, error) {
+	ps, err := f.PipelineStatuses()
 	if err != nil {
 		return nil, err
 	}
 
-	var ps []struct {
-		Name string `json:"name"`
+	names := make([]string, len(ps))
+	for i, p := range ps {
+		names[i] = p.Name
 	}
 
-	err = json.Unmarshal(psOut, &ps)
+	return names, nil
+}
+
+func (f *command) PipelineStatuses() ([]PipelineStatus, error) {
+	psOut, err := f.run("pipelines", "--json")
 	if err != nil {
 		return nil, err
 	}
 
-	names := make([]string, len(ps))
-	for i, p := range ps {
-		names[i] = p.Name
+	var ps []PipelineStatus
+
+	err = json.Unmarshal(psOut, &ps)
+	if err != nil {
+		return nil, err
 	}
 
-	return names, nil
+	return ps, nil
 }
 
-func (f command) GetPipeline(pipelineName string) ([]byte, error) {
-	return f.run(
+func (f *command) GetPipeline(pipelineName string, asJSON bool) ([]byte, error) {
+	args := []string{
 		"get-pipeline",
 		"-p", pipelineName,
-	)
+	}
+
+	if asJSON {
+		args = append(args, "--json")
+	}
+
+	return f.run(args...)
+}
+
+func (f *command) ValidatePipeline(
+	configFilepath string,
+	varsFilepaths []string,
+	vars map[string]interface{},
+	strict bool,
+) ([]byte, error) {
+	allArgs := []string{
+		"validate-pipeline",
+		"-c", configFilepath,
+	}
+
+	varsArgs, err := varsArgs(varsFilepaths, vars)
+	if err != nil {
+		return nil, err
+	}
+	allArgs = append(allArgs, varsArgs...)
+
+	if strict {
+		allArgs = append(allArgs, "--strict")
+	}
+
+	return f.run(allArgs...)
+}
+
+// RenderPipeline returns the config with the given vars interpolated, as it
+// would be set.
+func (f *command) RenderPipeline(
+	configFilepath string,
+	varsFilepaths []string,
+	vars map[string]interface{},
+) ([]byte, error) {
+	allArgs := []string{
+		"validate-pipeline",
+		"-c", configFilepath,
+	}
+
+	varsArgs, err := varsArgs(varsFilepaths, vars)
+	if err != nil {
+		return nil, err
+	}
+	allArgs = append(allArgs, varsArgs...)
+
+	allArgs = append(allArgs, "--output")
+
+	return f.run(allArgs...)
 }
 
-func (f command) SetPipeline(
+func (f *command) SetPipeline(
 	pipelineName string,
 	configFilepath string,
 	varsFilepaths []string,
 	vars map[string]interface{},
+	checkCreds bool,
 ) ([]byte, error) {
 	allArgs := []string{
 		"set-pipeline",
@@ -120,31 +239,27 @@ func (f command) SetPipeline(
 		"-c", configFilepath,
 	}
 
-	for _, vf := range varsFilepaths {
-		allArgs = append(allArgs, "-l", vf)
+	varsArgs, err := varsArgs(varsFilepaths, vars)
+	if err != nil {
+		return nil, err
 	}
+	allArgs = append(allArgs, varsArgs...)
 
-	for key, value := range vars {
-		payload, err := json.Marshal(value)
-
-		if err != nil {
-			return nil, err
-		}
-
-		allArgs = append(allArgs, "-y", fmt.Sprintf("%s=%s", key, payload))
+	if checkCreds {
+		allArgs = append(allArgs, "--check-creds")
 	}
 
 	return f.run(allArgs...)
 }
 
-func (f command) UnpausePipeline(pipelineName string) ([]byte, error) {
+func (f *command) UnpausePipeline(pipelineName string) ([]byte, error) {
 	return f.run(
 		"unpause-pipeline",
 		"-p", pipelineName,
 	)
 }
 
-func (f command) DestroyPipeline(pipelineName string) ([]byte, error) {
+func (f *command) DestroyPipeline(pipelineName string) ([]byte, error) {
 	return f.run(
 		"destroy-pipeline",
 		"-n",
@@ -152,22 +267,149 @@ func (f command) DestroyPipeline(pipelineName string) ([]byte, error) {
 	)
 }
 
-func (f command) ExposePipeline(pipelineName string) ([]byte, error) {
+func (f *command) RenamePipeline(oldName string, newName string) ([]byte, error) {
+	return f.run(
+		"rename-pipeline",
+		"-o", oldName,
+		"-n", newName,
+	)
+}
+
+func (f *command) ExposePipeline(pipelineName string) ([]byte, error) {
 	return f.run(
 		"expose-pipeline",
 		"-p", pipelineName,
 	)
 }
 
-func (f command) run(args ...string) ([]byte, error) {
+func varsArgs(varsFilepaths []string, vars map[string]interface{}) ([]string, error) {
+	var args []string
+
+	for _, vf := range varsFilepaths {
+		args = append(args, "-l", vf)
+	}
+
+	for key, value := range vars {
+		payload, err := json.Marshal(value)
+
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, "-y", fmt.Sprintf("%s=%s", key, payload))
+	}
+
+	return args, nil
+}
+
+// valid returns true if the session's token has not expired. Tokens whose
+// expiry is unknown are assumed to remain valid for the duration of the run.
+func (s session) valid() bool {
+	return s.expiry.IsZero() || time.Now().Add(expiryMargin).Before(s.expiry)
+}
+
+// targetName returns the fly target for the team which is currently logged
+// in, or the base target if no team has logged in yet.
+func (f *command) targetName() string {
+	if f.team == "" {
+		return f.target
+	}
+	return fmt.Sprintf("%s-%s", f.target, f.team)
+}
+
+// tokenExpiry returns the expiry of the token fly saved for the current
+// target, or the zero time if it cannot be determined.
+func (f *command) tokenExpiry() time.Time {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return time.Time{}
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(home, ".flyrc"))
+	if err != nil {
+		f.logger.Debugf("Unable to read .flyrc: %v\n", err)
+		return time.Time{}
+	}
+
+	var flyrc struct {
+		Targets map[string]struct {
+			Token struct {
+				Value string `yaml:"value"`
+			} `yaml:"token"`
+		} `yaml:"targets"`
+	}
+
+	err = yaml.Unmarshal(b, &flyrc)
+	if err != nil {
+		f.logger.Debugf("Unable to parse .flyrc: %v\n", err)
+		return time.Time{}
+	}
+
+	parts := strings.Split(flyrc.Targets[f.targetName()].Token.Value, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+
+	err = json.Unmarshal(payload, &claims)
+	if err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(claims.Exp, 0)
+}
+
+// versionsMatch returns true if the version of fly is the same as the version
+// of the ATC at url, in which case there is no need to run fly sync.
+func (f *command) versionsMatch(url string) bool {
+	flyVersion, err := f.exec("--version")
+	if err != nil {
+		f.logger.Debugf("Unable to get fly version: %v\n", err)
+		return false
+	}
+
+	resp, err := http.Get(strings.TrimRight(url, "/") + infoPath)
+	if err != nil {
+		f.logger.Debugf("Unable to get ATC version: %v\n", err)
+		return false
+	}
+	defer resp.Body.Close()
+
+	var info struct {
+		Version string `json:"version"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&info)
+	if err != nil || info.Version == "" {
+		f.logger.Debugf("Unable to get ATC version: %v\n", err)
+		return false
+	}
+
+	f.logger.Debugf("fly version: %s, ATC version: %s\n", strings.TrimSpace(string(flyVersion)), info.Version)
+
+	return strings.TrimSpace(string(flyVersion)) == info.Version
+}
+
+func (f *command) run(args ...string) ([]byte, error) {
 	if f.target == "" {
 		return nil, fmt.Errorf("target cannot be empty in command.run")
 	}
 
 	defaultArgs := []string{
-		"-t", f.target,
+		"-t", f.targetName(),
 	}
-	allArgs := append(defaultArgs, args...)
+	return f.exec(append(defaultArgs, args...)...)
+}
+
+func (f *command) exec(allArgs ...string) ([]byte, error) {
 	cmd := exec.Command(f.flyBinaryPath, allArgs...)
 
 	outbuf := bytes.NewBuffer(nil)
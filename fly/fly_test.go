@@ -1,10 +1,14 @@
 package fly_test
 
 import (
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/concourse/concourse-pipeline-resource/fly"
 	"github.com/concourse/concourse-pipeline-resource/logger/loggerfakes"
@@ -32,6 +36,8 @@ var _ = Describe("Command", func() {
 		fakeFlyContents string
 
 		fakeLogger *loggerfakes.FakeLogger
+
+		originalHome string
 	)
 
 	BeforeEach(func() {
@@ -48,6 +54,10 @@ var _ = Describe("Command", func() {
 		echo $@`
 
 		fakeLogger = &loggerfakes.FakeLogger{}
+
+		originalHome = os.Getenv("HOME")
+		err = os.Setenv("HOME", tempDir)
+		Expect(err).NotTo(HaveOccurred())
 	})
 
 	JustBeforeEach(func() {
@@ -58,16 +68,20 @@ var _ = Describe("Command", func() {
 	})
 
 	AfterEach(func() {
-		err := os.RemoveAll(tempDir)
+		err := os.Setenv("HOME", originalHome)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = os.RemoveAll(tempDir)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
 	Describe("Login", func() {
 		var (
-			url      string
-			username string
-			password string
-			insecure bool
+			url        string
+			username   string
+			password   string
+			insecure   bool
+			teamTarget string
 		)
 
 		BeforeEach(func() {
@@ -75,6 +89,7 @@ var _ = Describe("Command", func() {
 			username = "some-username"
 			password = "some-password"
 			insecure = false
+			teamTarget = fmt.Sprintf("%s-%s", target, teamName)
 		})
 
 		It("returns output without error", func() {
@@ -83,13 +98,13 @@ var _ = Describe("Command", func() {
 
 			expectedOutput := fmt.Sprintf(
 				"%s %s %s %s %s %s %s %s %s %s %s\n%s %s %s\n",
-				"-t", target,
+				"-t", teamTarget,
 				"login",
 				"-c", url,
 				"-n", teamName,
 				"-u", username,
 				"-p", password,
-				"-t", target,
+				"-t", teamTarget,
 				"sync",
 			)
 
@@ -107,14 +122,14 @@ var _ = Describe("Command", func() {
 
 				expectedOutput := fmt.Sprintf(
 					"%s %s %s %s %s %s %s %s %s %s %s %s\n%s %s %s\n",
-					"-t", target,
+					"-t", teamTarget,
 					"login",
 					"-c", url,
 					"-n", teamName,
 					"-u", username,
 					"-p", password,
 					"-k",
-					"-t", target,
+					"-t", teamTarget,
 					"sync",
 				)
 
@@ -145,11 +160,11 @@ var _ = Describe("Command", func() {
 
 				expectedOutput := fmt.Sprintf(
 					"%s %s %s %s %s %s %s\n%s %s %s\n",
-					"-t", target,
+					"-t", teamTarget,
 					"login",
 					"-c", url,
 					"-n", teamName,
-					"-t", target,
+					"-t", teamTarget,
 					"sync",
 				)
 
@@ -169,6 +184,116 @@ var _ = Describe("Command", func() {
 				Expect(err.Error()).To(MatchRegexp(".*some err output.*"))
 			})
 		})
+
+		It("runs subsequent commands against the team's target", func() {
+			_, err := flyCommand.Login(url, teamName, username, password, insecure)
+			Expect(err).NotTo(HaveOccurred())
+
+			output, err := flyCommand.UnpausePipeline("some-pipeline")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(string(output)).To(HavePrefix("-t %s unpause-pipeline", teamTarget))
+		})
+
+		Context("when the team has already logged in", func() {
+			JustBeforeEach(func() {
+				_, err := flyCommand.Login(url, teamName, username, password, insecure)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = flyCommand.Login(url, "other-team", username, password, insecure)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("reuses the session without logging in again", func() {
+				output, err := flyCommand.Login(url, teamName, username, password, insecure)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(output).To(BeEmpty())
+
+				output, err = flyCommand.UnpausePipeline("some-pipeline")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(string(output)).To(HavePrefix("-t %s unpause-pipeline", teamTarget))
+			})
+
+			It("only syncs once", func() {
+				output, err := flyCommand.Login(url, "another-team", username, password, insecure)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(string(output)).NotTo(ContainSubstring("sync"))
+			})
+
+			Context("when the session's token has expired", func() {
+				BeforeEach(func() {
+					claims := base64.RawURLEncoding.EncodeToString([]byte(
+						fmt.Sprintf(`{"exp":%d}`, time.Now().Add(-time.Hour).Unix()),
+					))
+
+					flyrc := fmt.Sprintf(`targets:
+  %s:
+    api: some-url
+    team: %s
+    token:
+      type: Bearer
+      value: header.%s.signature
+`, teamTarget, teamName, claims)
+
+					err := ioutil.WriteFile(filepath.Join(tempDir, ".flyrc"), []byte(flyrc), os.ModePerm)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("logs in again", func() {
+					output, err := flyCommand.Login(url, teamName, username, password, insecure)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(string(output)).To(HavePrefix("-t %s login", teamTarget))
+				})
+			})
+		})
+
+		Context("when the fly and ATC versions match", func() {
+			var (
+				server *httptest.Server
+			)
+
+			BeforeEach(func() {
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					defer GinkgoRecover()
+					Expect(r.URL.Path).To(Equal("/api/v1/info"))
+					w.Write([]byte(`{"version":"1.2.3"}`))
+				}))
+				url = server.URL
+
+				fakeFlyContents = `#!/bin/sh
+if [ "$1" = "--version" ]; then
+  echo "1.2.3"
+else
+  echo $@
+fi
+`
+			})
+
+			AfterEach(func() {
+				server.Close()
+			})
+
+			It("does not sync", func() {
+				output, err := flyCommand.Login(url, teamName, username, password, insecure)
+				Expect(err).NotTo(HaveOccurred())
+
+				expectedOutput := fmt.Sprintf(
+					"%s %s %s %s %s %s %s %s %s %s %s\n",
+					"-t", teamTarget,
+					"login",
+					"-c", url,
+					"-n", teamName,
+					"-u", username,
+					"-p", password,
+				)
+
+				Expect(string(output)).To(Equal(expectedOutput))
+			})
+		})
 	})
 
 	Describe("Pipelines", func() {
@@ -186,6 +311,37 @@ echo '[{"name":"abc"},{"name":"def"}]'
 		})
 	})
 
+	Describe("PipelineStatuses", func() {
+		BeforeEach(func() {
+			fakeFlyContents = `#!/bin/sh
+echo '[{"name":"abc","paused":true,"public":false},{"name":"def","paused":false,"public":true}]'
+`
+		})
+
+		It("returns pipelines with their paused and public state without error", func() {
+			pipelines, err := flyCommand.PipelineStatuses()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(pipelines).To(Equal([]fly.PipelineStatus{
+				{Name: "abc", Paused: true, Public: false},
+				{Name: "def", Paused: false, Public: true},
+			}))
+		})
+
+		Context("when the output fails to parse", func() {
+			BeforeEach(func() {
+				fakeFlyContents = `#!/bin/sh
+echo '{{'
+`
+			})
+
+			It("returns an error", func() {
+				_, err := flyCommand.PipelineStatuses()
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
 	Describe("GetPipeline", func() {
 		var (
 			pipelineName string
@@ -196,7 +352,7 @@ echo '[{"name":"abc"},{"name":"def"}]'
 		})
 
 		It("returns output without error", func() {
-			output, err := flyCommand.GetPipeline(pipelineName)
+			output, err := flyCommand.GetPipeline(pipelineName, false)
 			Expect(err).NotTo(HaveOccurred())
 
 			expectedOutput := fmt.Sprintf(
@@ -208,6 +364,23 @@ echo '[{"name":"abc"},{"name":"def"}]'
 
 			Expect(string(output)).To(Equal(expectedOutput))
 		})
+
+		Context("when json is requested", func() {
+			It("adds --json flag to command", func() {
+				output, err := flyCommand.GetPipeline(pipelineName, true)
+				Expect(err).NotTo(HaveOccurred())
+
+				expectedOutput := fmt.Sprintf(
+					"%s %s %s %s %s %s\n",
+					"-t", target,
+					"get-pipeline",
+					"-p", pipelineName,
+					"--json",
+				)
+
+				Expect(string(output)).To(Equal(expectedOutput))
+			})
+		})
 	})
 
 	Describe("SetPipeline", func() {
@@ -222,7 +395,7 @@ echo '[{"name":"abc"},{"name":"def"}]'
 		})
 
 		It("returns output without error", func() {
-			output, err := flyCommand.SetPipeline(pipelineName, configFilepath, nil, nil)
+			output, err := flyCommand.SetPipeline(pipelineName, configFilepath, nil, nil, false)
 			Expect(err).NotTo(HaveOccurred())
 
 			expectedOutput := fmt.Sprintf(
@@ -254,7 +427,7 @@ echo '[{"name":"abc"},{"name":"def"}]'
 			})
 
 			It("returns output without error", func() {
-				output, err := flyCommand.SetPipeline(pipelineName, configFilepath, nil, vars)
+				output, err := flyCommand.SetPipeline(pipelineName, configFilepath, nil, vars, false)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(string(output)).To(HavePrefix("-t %s set-pipeline", target))
@@ -280,7 +453,7 @@ echo '[{"name":"abc"},{"name":"def"}]'
 			})
 
 			It("returns output without error", func() {
-				output, err := flyCommand.SetPipeline(pipelineName, configFilepath, varsFiles, nil)
+				output, err := flyCommand.SetPipeline(pipelineName, configFilepath, varsFiles, nil, false)
 				Expect(err).NotTo(HaveOccurred())
 
 				expectedOutput := fmt.Sprintf(
@@ -297,6 +470,121 @@ echo '[{"name":"abc"},{"name":"def"}]'
 				Expect(string(output)).To(Equal(expectedOutput))
 			})
 		})
+
+		Context("when check creds is true", func() {
+			It("adds --check-creds flag to command", func() {
+				output, err := flyCommand.SetPipeline(pipelineName, configFilepath, nil, nil, true)
+				Expect(err).NotTo(HaveOccurred())
+
+				expectedOutput := fmt.Sprintf(
+					"%s %s %s %s %s %s %s %s %s\n",
+					"-t", target,
+					"set-pipeline",
+					"-n",
+					"-p", pipelineName,
+					"-c", configFilepath,
+					"--check-creds",
+				)
+
+				Expect(string(output)).To(Equal(expectedOutput))
+			})
+		})
+	})
+
+	Describe("ValidatePipeline", func() {
+		var (
+			configFilepath string
+			varsFiles      []string
+			vars           map[string]interface{}
+		)
+
+		BeforeEach(func() {
+			configFilepath = "some-config-file"
+			varsFiles = []string{"vars-file-1"}
+			vars = map[string]interface{}{
+				"launch-missiles": true,
+			}
+		})
+
+		It("returns output without error", func() {
+			output, err := flyCommand.ValidatePipeline(configFilepath, varsFiles, vars, false)
+			Expect(err).NotTo(HaveOccurred())
+
+			expectedOutput := fmt.Sprintf(
+				"%s %s %s %s %s %s %s %s %s\n",
+				"-t", target,
+				"validate-pipeline",
+				"-c", configFilepath,
+				"-l", varsFiles[0],
+				"-y", "launch-missiles=true",
+			)
+
+			Expect(string(output)).To(Equal(expectedOutput))
+		})
+
+		Context("when strict is true", func() {
+			It("adds --strict flag to command", func() {
+				output, err := flyCommand.ValidatePipeline(configFilepath, nil, nil, true)
+				Expect(err).NotTo(HaveOccurred())
+
+				expectedOutput := fmt.Sprintf(
+					"%s %s %s %s %s %s\n",
+					"-t", target,
+					"validate-pipeline",
+					"-c", configFilepath,
+					"--strict",
+				)
+
+				Expect(string(output)).To(Equal(expectedOutput))
+			})
+		})
+
+		Context("when the command returns an error", func() {
+			BeforeEach(func() {
+				fakeFlyContents = errScript
+			})
+
+			It("appends stderr to the error", func() {
+				_, err := flyCommand.ValidatePipeline(configFilepath, nil, nil, false)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(MatchRegexp(".*some err output.*"))
+			})
+		})
+	})
+
+	Describe("RenderPipeline", func() {
+		It("returns the output of validate-pipeline with --output", func() {
+			output, err := flyCommand.RenderPipeline("some-config-file", []string{"vars-file-1"}, map[string]interface{}{
+				"launch-missiles": true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			expectedOutput := fmt.Sprintf(
+				"%s %s %s %s %s %s %s %s %s %s\n",
+				"-t", target,
+				"validate-pipeline",
+				"-c", "some-config-file",
+				"-l", "vars-file-1",
+				"-y", "launch-missiles=true",
+				"--output",
+			)
+
+			Expect(string(output)).To(Equal(expectedOutput))
+		})
+
+		Context("when the command returns an error", func() {
+			BeforeEach(func() {
+				fakeFlyContents = errScript
+			})
+
+			It("appends stderr to the error", func() {
+				_, err := flyCommand.RenderPipeline("some-config-file", nil, nil)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(MatchRegexp(".*some err output.*"))
+			})
+		})
 	})
 
 	Describe("DestroyPipeline", func() {
@@ -324,6 +612,33 @@ echo '[{"name":"abc"},{"name":"def"}]'
 		})
 	})
 
+	Describe("RenamePipeline", func() {
+		var (
+			oldName string
+			newName string
+		)
+
+		BeforeEach(func() {
+			oldName = "some-old-pipeline"
+			newName = "some-new-pipeline"
+		})
+
+		It("returns output without error", func() {
+			output, err := flyCommand.RenamePipeline(oldName, newName)
+			Expect(err).NotTo(HaveOccurred())
+
+			expectedOutput := fmt.Sprintf(
+				"%s %s %s %s %s %s %s\n",
+				"-t", target,
+				"rename-pipeline",
+				"-o", oldName,
+				"-n", newName,
+			)
+
+			Expect(string(output)).To(Equal(expectedOutput))
+		})
+	})
+
 	Describe("UnpausePipeline", func() {
 		var (
 			pipelineName string
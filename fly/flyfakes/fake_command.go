@@ -34,10 +34,11 @@ type FakeCommand struct {
 		result1 []byte
 		result2 error
 	}
-	GetPipelineStub        func(string) ([]byte, error)
+	GetPipelineStub        func(string, bool) ([]byte, error)
 	getPipelineMutex       sync.RWMutex
 	getPipelineArgsForCall []struct {
 		arg1 string
+		arg2 bool
 	}
 	getPipelineReturns struct {
 		result1 []byte
@@ -64,6 +65,18 @@ type FakeCommand struct {
 		result1 []byte
 		result2 error
 	}
+	PipelineStatusesStub        func() ([]fly.PipelineStatus, error)
+	pipelineStatusesMutex       sync.RWMutex
+	pipelineStatusesArgsForCall []struct {
+	}
+	pipelineStatusesReturns struct {
+		result1 []fly.PipelineStatus
+		result2 error
+	}
+	pipelineStatusesReturnsOnCall map[int]struct {
+		result1 []fly.PipelineStatus
+		result2 error
+	}
 	PipelinesStub        func() ([]string, error)
 	pipelinesMutex       sync.RWMutex
 	pipelinesArgsForCall []struct {
@@ -76,13 +89,43 @@ type FakeCommand struct {
 		result1 []string
 		result2 error
 	}
-	SetPipelineStub        func(string, string, []string, map[string]interface{}) ([]byte, error)
+	RenamePipelineStub        func(string, string) ([]byte, error)
+	renamePipelineMutex       sync.RWMutex
+	renamePipelineArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	renamePipelineReturns struct {
+		result1 []byte
+		result2 error
+	}
+	renamePipelineReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	RenderPipelineStub        func(string, []string, map[string]interface{}) ([]byte, error)
+	renderPipelineMutex       sync.RWMutex
+	renderPipelineArgsForCall []struct {
+		arg1 string
+		arg2 []string
+		arg3 map[string]interface{}
+	}
+	renderPipelineReturns struct {
+		result1 []byte
+		result2 error
+	}
+	renderPipelineReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	SetPipelineStub        func(string, string, []string, map[string]interface{}, bool) ([]byte, error)
 	setPipelineMutex       sync.RWMutex
 	setPipelineArgsForCall []struct {
 		arg1 string
 		arg2 string
 		arg3 []string
 		arg4 map[string]interface{}
+		arg5 bool
 	}
 	setPipelineReturns struct {
 		result1 []byte
@@ -105,6 +148,22 @@ type FakeCommand struct {
 		result1 []byte
 		result2 error
 	}
+	ValidatePipelineStub        func(string, []string, map[string]interface{}, bool) ([]byte, error)
+	validatePipelineMutex       sync.RWMutex
+	validatePipelineArgsForCall []struct {
+		arg1 string
+		arg2 []string
+		arg3 map[string]interface{}
+		arg4 bool
+	}
+	validatePipelineReturns struct {
+		result1 []byte
+		result2 error
+	}
+	validatePipelineReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -235,16 +294,17 @@ func (fake *FakeCommand) ExposePipelineReturnsOnCall(i int, result1 []byte, resu
 	}{result1, result2}
 }
 
-func (fake *FakeCommand) GetPipeline(arg1 string) ([]byte, error) {
+func (fake *FakeCommand) GetPipeline(arg1 string, arg2 bool) ([]byte, error) {
 	fake.getPipelineMutex.Lock()
 	ret, specificReturn := fake.getPipelineReturnsOnCall[len(fake.getPipelineArgsForCall)]
 	fake.getPipelineArgsForCall = append(fake.getPipelineArgsForCall, struct {
 		arg1 string
-	}{arg1})
-	fake.recordInvocation("GetPipeline", []interface{}{arg1})
+		arg2 bool
+	}{arg1, arg2})
+	fake.recordInvocation("GetPipeline", []interface{}{arg1, arg2})
 	fake.getPipelineMutex.Unlock()
 	if fake.GetPipelineStub != nil {
-		return fake.GetPipelineStub(arg1)
+		return fake.GetPipelineStub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -259,17 +319,17 @@ func (fake *FakeCommand) GetPipelineCallCount() int {
 	return len(fake.getPipelineArgsForCall)
 }
 
-func (fake *FakeCommand) GetPipelineCalls(stub func(string) ([]byte, error)) {
+func (fake *FakeCommand) GetPipelineCalls(stub func(string, bool) ([]byte, error)) {
 	fake.getPipelineMutex.Lock()
 	defer fake.getPipelineMutex.Unlock()
 	fake.GetPipelineStub = stub
 }
 
-func (fake *FakeCommand) GetPipelineArgsForCall(i int) string {
+func (fake *FakeCommand) GetPipelineArgsForCall(i int) (string, bool) {
 	fake.getPipelineMutex.RLock()
 	defer fake.getPipelineMutex.RUnlock()
 	argsForCall := fake.getPipelineArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeCommand) GetPipelineReturns(result1 []byte, result2 error) {
@@ -365,6 +425,61 @@ func (fake *FakeCommand) LoginReturnsOnCall(i int, result1 []byte, result2 error
 	}{result1, result2}
 }
 
+func (fake *FakeCommand) PipelineStatuses() ([]fly.PipelineStatus, error) {
+	fake.pipelineStatusesMutex.Lock()
+	ret, specificReturn := fake.pipelineStatusesReturnsOnCall[len(fake.pipelineStatusesArgsForCall)]
+	fake.pipelineStatusesArgsForCall = append(fake.pipelineStatusesArgsForCall, struct {
+	}{})
+	fake.recordInvocation("PipelineStatuses", []interface{}{})
+	fake.pipelineStatusesMutex.Unlock()
+	if fake.PipelineStatusesStub != nil {
+		return fake.PipelineStatusesStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.pipelineStatusesReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCommand) PipelineStatusesCallCount() int {
+	fake.pipelineStatusesMutex.RLock()
+	defer fake.pipelineStatusesMutex.RUnlock()
+	return len(fake.pipelineStatusesArgsForCall)
+}
+
+func (fake *FakeCommand) PipelineStatusesCalls(stub func() ([]fly.PipelineStatus, error)) {
+	fake.pipelineStatusesMutex.Lock()
+	defer fake.pipelineStatusesMutex.Unlock()
+	fake.PipelineStatusesStub = stub
+}
+
+func (fake *FakeCommand) PipelineStatusesReturns(result1 []fly.PipelineStatus, result2 error) {
+	fake.pipelineStatusesMutex.Lock()
+	defer fake.pipelineStatusesMutex.Unlock()
+	fake.PipelineStatusesStub = nil
+	fake.pipelineStatusesReturns = struct {
+		result1 []fly.PipelineStatus
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCommand) PipelineStatusesReturnsOnCall(i int, result1 []fly.PipelineStatus, result2 error) {
+	fake.pipelineStatusesMutex.Lock()
+	defer fake.pipelineStatusesMutex.Unlock()
+	fake.PipelineStatusesStub = nil
+	if fake.pipelineStatusesReturnsOnCall == nil {
+		fake.pipelineStatusesReturnsOnCall = make(map[int]struct {
+			result1 []fly.PipelineStatus
+			result2 error
+		})
+	}
+	fake.pipelineStatusesReturnsOnCall[i] = struct {
+		result1 []fly.PipelineStatus
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeCommand) Pipelines() ([]string, error) {
 	fake.pipelinesMutex.Lock()
 	ret, specificReturn := fake.pipelinesReturnsOnCall[len(fake.pipelinesArgsForCall)]
@@ -420,7 +535,141 @@ func (fake *FakeCommand) PipelinesReturnsOnCall(i int, result1 []string, result2
 	}{result1, result2}
 }
 
-func (fake *FakeCommand) SetPipeline(arg1 string, arg2 string, arg3 []string, arg4 map[string]interface{}) ([]byte, error) {
+func (fake *FakeCommand) RenamePipeline(arg1 string, arg2 string) ([]byte, error) {
+	fake.renamePipelineMutex.Lock()
+	ret, specificReturn := fake.renamePipelineReturnsOnCall[len(fake.renamePipelineArgsForCall)]
+	fake.renamePipelineArgsForCall = append(fake.renamePipelineArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("RenamePipeline", []interface{}{arg1, arg2})
+	fake.renamePipelineMutex.Unlock()
+	if fake.RenamePipelineStub != nil {
+		return fake.RenamePipelineStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.renamePipelineReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCommand) RenamePipelineCallCount() int {
+	fake.renamePipelineMutex.RLock()
+	defer fake.renamePipelineMutex.RUnlock()
+	return len(fake.renamePipelineArgsForCall)
+}
+
+func (fake *FakeCommand) RenamePipelineCalls(stub func(string, string) ([]byte, error)) {
+	fake.renamePipelineMutex.Lock()
+	defer fake.renamePipelineMutex.Unlock()
+	fake.RenamePipelineStub = stub
+}
+
+func (fake *FakeCommand) RenamePipelineArgsForCall(i int) (string, string) {
+	fake.renamePipelineMutex.RLock()
+	defer fake.renamePipelineMutex.RUnlock()
+	argsForCall := fake.renamePipelineArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCommand) RenamePipelineReturns(result1 []byte, result2 error) {
+	fake.renamePipelineMutex.Lock()
+	defer fake.renamePipelineMutex.Unlock()
+	fake.RenamePipelineStub = nil
+	fake.renamePipelineReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCommand) RenamePipelineReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.renamePipelineMutex.Lock()
+	defer fake.renamePipelineMutex.Unlock()
+	fake.RenamePipelineStub = nil
+	if fake.renamePipelineReturnsOnCall == nil {
+		fake.renamePipelineReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.renamePipelineReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCommand) RenderPipeline(arg1 string, arg2 []string, arg3 map[string]interface{}) ([]byte, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.renderPipelineMutex.Lock()
+	ret, specificReturn := fake.renderPipelineReturnsOnCall[len(fake.renderPipelineArgsForCall)]
+	fake.renderPipelineArgsForCall = append(fake.renderPipelineArgsForCall, struct {
+		arg1 string
+		arg2 []string
+		arg3 map[string]interface{}
+	}{arg1, arg2Copy, arg3})
+	fake.recordInvocation("RenderPipeline", []interface{}{arg1, arg2Copy, arg3})
+	fake.renderPipelineMutex.Unlock()
+	if fake.RenderPipelineStub != nil {
+		return fake.RenderPipelineStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.renderPipelineReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCommand) RenderPipelineCallCount() int {
+	fake.renderPipelineMutex.RLock()
+	defer fake.renderPipelineMutex.RUnlock()
+	return len(fake.renderPipelineArgsForCall)
+}
+
+func (fake *FakeCommand) RenderPipelineCalls(stub func(string, []string, map[string]interface{}) ([]byte, error)) {
+	fake.renderPipelineMutex.Lock()
+	defer fake.renderPipelineMutex.Unlock()
+	fake.RenderPipelineStub = stub
+}
+
+func (fake *FakeCommand) RenderPipelineArgsForCall(i int) (string, []string, map[string]interface{}) {
+	fake.renderPipelineMutex.RLock()
+	defer fake.renderPipelineMutex.RUnlock()
+	argsForCall := fake.renderPipelineArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeCommand) RenderPipelineReturns(result1 []byte, result2 error) {
+	fake.renderPipelineMutex.Lock()
+	defer fake.renderPipelineMutex.Unlock()
+	fake.RenderPipelineStub = nil
+	fake.renderPipelineReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCommand) RenderPipelineReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.renderPipelineMutex.Lock()
+	defer fake.renderPipelineMutex.Unlock()
+	fake.RenderPipelineStub = nil
+	if fake.renderPipelineReturnsOnCall == nil {
+		fake.renderPipelineReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.renderPipelineReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCommand) SetPipeline(arg1 string, arg2 string, arg3 []string, arg4 map[string]interface{}, arg5 bool) ([]byte, error) {
 	var arg3Copy []string
 	if arg3 != nil {
 		arg3Copy = make([]string, len(arg3))
@@ -433,11 +682,12 @@ func (fake *FakeCommand) SetPipeline(arg1 string, arg2 string, arg3 []string, ar
 		arg2 string
 		arg3 []string
 		arg4 map[string]interface{}
-	}{arg1, arg2, arg3Copy, arg4})
-	fake.recordInvocation("SetPipeline", []interface{}{arg1, arg2, arg3Copy, arg4})
+		arg5 bool
+	}{arg1, arg2, arg3Copy, arg4, arg5})
+	fake.recordInvocation("SetPipeline", []interface{}{arg1, arg2, arg3Copy, arg4, arg5})
 	fake.setPipelineMutex.Unlock()
 	if fake.SetPipelineStub != nil {
-		return fake.SetPipelineStub(arg1, arg2, arg3, arg4)
+		return fake.SetPipelineStub(arg1, arg2, arg3, arg4, arg5)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -452,17 +702,17 @@ func (fake *FakeCommand) SetPipelineCallCount() int {
 	return len(fake.setPipelineArgsForCall)
 }
 
-func (fake *FakeCommand) SetPipelineCalls(stub func(string, string, []string, map[string]interface{}) ([]byte, error)) {
+func (fake *FakeCommand) SetPipelineCalls(stub func(string, string, []string, map[string]interface{}, bool) ([]byte, error)) {
 	fake.setPipelineMutex.Lock()
 	defer fake.setPipelineMutex.Unlock()
 	fake.SetPipelineStub = stub
 }
 
-func (fake *FakeCommand) SetPipelineArgsForCall(i int) (string, string, []string, map[string]interface{}) {
+func (fake *FakeCommand) SetPipelineArgsForCall(i int) (string, string, []string, map[string]interface{}, bool) {
 	fake.setPipelineMutex.RLock()
 	defer fake.setPipelineMutex.RUnlock()
 	argsForCall := fake.setPipelineArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
 func (fake *FakeCommand) SetPipelineReturns(result1 []byte, result2 error) {
@@ -554,6 +804,77 @@ func (fake *FakeCommand) UnpausePipelineReturnsOnCall(i int, result1 []byte, res
 	}{result1, result2}
 }
 
+func (fake *FakeCommand) ValidatePipeline(arg1 string, arg2 []string, arg3 map[string]interface{}, arg4 bool) ([]byte, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.validatePipelineMutex.Lock()
+	ret, specificReturn := fake.validatePipelineReturnsOnCall[len(fake.validatePipelineArgsForCall)]
+	fake.validatePipelineArgsForCall = append(fake.validatePipelineArgsForCall, struct {
+		arg1 string
+		arg2 []string
+		arg3 map[string]interface{}
+		arg4 bool
+	}{arg1, arg2Copy, arg3, arg4})
+	fake.recordInvocation("ValidatePipeline", []interface{}{arg1, arg2Copy, arg3, arg4})
+	fake.validatePipelineMutex.Unlock()
+	if fake.ValidatePipelineStub != nil {
+		return fake.ValidatePipelineStub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.validatePipelineReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCommand) ValidatePipelineCallCount() int {
+	fake.validatePipelineMutex.RLock()
+	defer fake.validatePipelineMutex.RUnlock()
+	return len(fake.validatePipelineArgsForCall)
+}
+
+func (fake *FakeCommand) ValidatePipelineCalls(stub func(string, []string, map[string]interface{}, bool) ([]byte, error)) {
+	fake.validatePipelineMutex.Lock()
+	defer fake.validatePipelineMutex.Unlock()
+	fake.ValidatePipelineStub = stub
+}
+
+func (fake *FakeCommand) ValidatePipelineArgsForCall(i int) (string, []string, map[string]interface{}, bool) {
+	fake.validatePipelineMutex.RLock()
+	defer fake.validatePipelineMutex.RUnlock()
+	argsForCall := fake.validatePipelineArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeCommand) ValidatePipelineReturns(result1 []byte, result2 error) {
+	fake.validatePipelineMutex.Lock()
+	defer fake.validatePipelineMutex.Unlock()
+	fake.ValidatePipelineStub = nil
+	fake.validatePipelineReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCommand) ValidatePipelineReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.validatePipelineMutex.Lock()
+	defer fake.validatePipelineMutex.Unlock()
+	fake.ValidatePipelineStub = nil
+	if fake.validatePipelineReturnsOnCall == nil {
+		fake.validatePipelineReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.validatePipelineReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeCommand) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -565,12 +886,20 @@ func (fake *FakeCommand) Invocations() map[string][][]interface{} {
 	defer fake.getPipelineMutex.RUnlock()
 	fake.loginMutex.RLock()
 	defer fake.loginMutex.RUnlock()
+	fake.pipelineStatusesMutex.RLock()
+	defer fake.pipelineStatusesMutex.RUnlock()
 	fake.pipelinesMutex.RLock()
 	defer fake.pipelinesMutex.RUnlock()
+	fake.renamePipelineMutex.RLock()
+	defer fake.renamePipelineMutex.RUnlock()
+	fake.renderPipelineMutex.RLock()
+	defer fake.renderPipelineMutex.RUnlock()
 	fake.setPipelineMutex.RLock()
 	defer fake.setPipelineMutex.RUnlock()
 	fake.unpausePipelineMutex.RLock()
 	defer fake.unpausePipelineMutex.RUnlock()
+	fake.validatePipelineMutex.RLock()
+	defer fake.validatePipelineMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
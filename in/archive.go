@@ -0,0 +1,102 @@
+package in
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	archiveFilename         = "pipelines.tar.gz"
+	archiveChecksumFilename = archiveFilename + ".sha256"
+	checksumsFilename       = "SHA256SUMS"
+)
+
+// writeArchive writes every file written so far to a gzipped tarball, along
+// with a SHA256SUMS file of their checksums, and writes the checksum of the
+// tarball itself alongside it. The tarball is reproducible: files are added
+// in sorted order with fixed timestamps, so identical configs always produce
+// an identical tarball. It returns the checksum of the tarball.
+func (c *Command) writeArchive(files *downloadFiles) (string, error) {
+	paths := append([]string{}, files.paths...)
+	sort.Strings(paths)
+
+	checksums := bytes.NewBuffer(nil)
+	contents := make(map[string][]byte)
+
+	for _, p := range paths {
+		b, err := ioutil.ReadFile(filepath.Join(files.dir, p))
+		if err != nil {
+			return "", err
+		}
+
+		contents[p] = b
+		fmt.Fprintf(checksums, "%x  %s\n", sha256.Sum256(b), filepath.ToSlash(p))
+	}
+
+	buf := bytes.NewBuffer(nil)
+	gzipWriter := gzip.NewWriter(buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	err := addToArchive(tarWriter, checksumsFilename, checksums.Bytes())
+	if err != nil {
+		return "", err
+	}
+
+	for _, p := range paths {
+		err = addToArchive(tarWriter, filepath.ToSlash(p), contents[p])
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = tarWriter.Close()
+	if err != nil {
+		// Untested as it is too hard to force tar.Writer.Close to error
+		return "", err
+	}
+
+	err = gzipWriter.Close()
+	if err != nil {
+		// Untested as it is too hard to force gzip.Writer.Close to error
+		return "", err
+	}
+
+	checksum := fmt.Sprintf("%x", sha256.Sum256(buf.Bytes()))
+
+	c.logger.Debugf("Writing pipelines archive to: %s\n", filepath.Join(files.dir, archiveFilename))
+	err = files.write(archiveFilename, buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+
+	err = files.write(archiveChecksumFilename, []byte(fmt.Sprintf("%s  %s\n", checksum, archiveFilename)))
+	if err != nil {
+		return "", err
+	}
+
+	return checksum, nil
+}
+
+func addToArchive(tarWriter *tar.Writer, name string, contents []byte) error {
+	err := tarWriter.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     fileMode,
+		Size:     int64(len(contents)),
+		ModTime:  time.Unix(0, 0),
+		Typeflag: tar.TypeReg,
+	})
+	if err != nil {
+		// Untested as the header is always valid
+		return err
+	}
+
+	_, err = tarWriter.Write(contents)
+	return err
+}
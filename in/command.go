@@ -1,19 +1,30 @@
 package in
 
 import (
+	"crypto/md5"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/concourse/concourse-pipeline-resource/concourse"
+	"github.com/concourse/concourse-pipeline-resource/config"
 	"github.com/concourse/concourse-pipeline-resource/fly"
 	"github.com/concourse/concourse-pipeline-resource/logger"
+	"github.com/concourse/concourse-pipeline-resource/redact"
+	"gopkg.in/yaml.v2"
 )
 
 const (
 	apiPrefix = "/api/v1"
+
+	formatJSON = "json"
+
+	manifestFilename   = "pipelines.yml"
+	redactionsFilename = "redactions.json"
+	inventoryFilename  = "inventory.json"
 )
 
 type Command struct {
@@ -46,13 +57,51 @@ func (c *Command) Run(input concourse.InRequest) (concourse.InResponse, error) {
 		}
 	}
 
-	teams := make(map[string]concourse.Team)
+	asJSON := input.Params.Format == formatJSON
+	extension := "yml"
+	if asJSON {
+		extension = "json"
+	}
 
-	for _, team := range input.Source.Teams {
-		teams[team.Name] = team
+	layout, err := newLayout(input.Params.Layout)
+	if err != nil {
+		return concourse.InResponse{}, err
 	}
 
-	for teamName, team := range teams {
+	var redactor *redact.Redactor
+	if input.Params.Redact {
+		redactor, err = redact.NewRedactor(input.Params.RedactKeys, input.Params.RedactPatterns)
+		if err != nil {
+			return concourse.InResponse{}, err
+		}
+	}
+
+	files := &downloadFiles{dir: c.downloadDir}
+
+	// Pipelines with the same name may be fetched from several teams, as
+	// versions are keyed by name only.
+	liveVersions := make(map[string][]string)
+	var manifest []manifestPipeline
+	redactions := []redactedPipeline{}
+	inventory := []inventoryPipeline{}
+	var graphPipelines []graphPipeline
+	liveConfigs := make(map[pipelineKey][]byte)
+
+	// Teams are processed in the order of source, so that pipelines whose
+	// file names would collide are always renamed in the same order.
+	seenTeams := make(map[string]bool)
+	for _, team := range input.Source.Teams {
+		teamName := team.Name
+		if seenTeams[teamName] {
+			continue
+		}
+		seenTeams[teamName] = true
+
+		if !selected(input.Params.Teams, teamName) {
+			c.logger.Debugf("Skipping unselected team: %s\n", teamName)
+			continue
+		}
+
 		c.logger.Debugf("Performing login\n")
 		_, err := c.flyCommand.Login(
 			input.Source.Target,
@@ -67,46 +116,326 @@ func (c *Command) Run(input concourse.InRequest) (concourse.InResponse, error) {
 
 		c.logger.Debugf("Login successful\n")
 
-		pipelines, err := c.flyCommand.Pipelines()
+		pipelines, err := c.flyCommand.PipelineStatuses()
 		if err != nil {
 			return concourse.InResponse{}, err
 		}
 		c.logger.Debugf("Found pipelines (%s): %+v\n", teamName, pipelines)
 
-		for _, pipelineName := range pipelines {
-			outContents, err := c.flyCommand.GetPipeline(pipelineName)
-			if err != nil {
-				return concourse.InResponse{}, err
+		for _, pipeline := range pipelines {
+			pipelineName := pipeline.Name
+
+			if !pipelineSelected(input.Params.Pipelines, teamName, pipelineName) {
+				c.logger.Debugf("Skipping unselected pipeline: %s/%s\n", teamName, pipelineName)
+				continue
 			}
-			pipelineContentsFilepath := filepath.Join(
-				c.downloadDir,
-				fmt.Sprintf(
-					"%s-%s.yml",
-					teamName,
-					pipelineName,
-				),
-			)
-			c.logger.Debugf(
-				"Writing pipeline contents to: %s\n",
-				pipelineContentsFilepath,
-			)
-			err = ioutil.WriteFile(pipelineContentsFilepath, outContents, os.ModePerm)
-			// Untested as it is too hard to force ioutil.WriteFile to error
+
+			outContents, err := c.flyCommand.GetPipeline(pipelineName, asJSON)
 			if err != nil {
 				return concourse.InResponse{}, err
 			}
+
+			if _, requested := input.Version[pipelineName]; requested {
+				// Versions are always computed from the yaml config, as in check.
+				yamlContents := outContents
+				if asJSON {
+					yamlContents, err = c.flyCommand.GetPipeline(pipelineName, false)
+					if err != nil {
+						return concourse.InResponse{}, err
+					}
+				}
+
+				liveVersions[pipelineName] = append(liveVersions[pipelineName], fmt.Sprintf("%x", md5.Sum(yamlContents)))
+			}
+
+			if input.Params.ExpectedManifest != "" {
+				liveConfigs[pipelineKey{teamName: teamName, name: pipelineName}] = outContents
+			}
+
+			if redactor != nil || input.Params.Normalize {
+				var paths []string
+				outContents, paths, err = rewriteConfig(outContents, asJSON, redactor, input.Params.Normalize)
+				if err != nil {
+					return concourse.InResponse{}, err
+				}
+
+				if len(paths) > 0 {
+					c.logger.Debugf("Redacted %d values from pipeline: %s/%s\n", len(paths), teamName, pipelineName)
+					redactions = append(redactions, redactedPipeline{
+						Name:     pipelineName,
+						TeamName: teamName,
+						Paths:    paths,
+					})
+				}
+			}
+
+			var parsed config.Pipeline
+			if input.Params.Inventory || input.Params.Docs || input.Params.Graph || input.Params.CrossPipelineGraph {
+				parsed, err = config.ParsePipeline(outContents)
+				if err != nil {
+					return concourse.InResponse{}, err
+				}
+			}
+
+			if input.Params.Inventory {
+				inventory = append(inventory, newInventoryPipeline(teamName, pipelineName, parsed))
+			}
+
+			entry := manifestPipeline{
+				Name:     pipelineName,
+				TeamName: teamName,
+				Unpaused: !pipeline.Paused,
+				Exposed:  pipeline.Public,
+			}
+
+			if input.Params.Split {
+				doc, err := config.Parse(outContents)
+				if err != nil {
+					return concourse.InResponse{}, err
+				}
+
+				pipelineDirname, err := layout.path(teamName, pipelineName, "")
+				if err != nil {
+					return concourse.InResponse{}, err
+				}
+
+				c.logger.Debugf(
+					"Writing split pipeline contents to: %s\n",
+					filepath.Join(c.downloadDir, pipelineDirname),
+				)
+				err = c.writeSplit(files, pipelineDirname, doc, extension, asJSON)
+				if err != nil {
+					return concourse.InResponse{}, err
+				}
+
+				// out reads config directories relative to the directory
+				// containing all inputs, in which this one is named after the
+				// resource.
+				entry.ConfigDir = filepath.Join(filepath.Base(c.downloadDir), pipelineDirname)
+			} else {
+				pipelineContentsFilename, err := layout.path(teamName, pipelineName, extension)
+				if err != nil {
+					return concourse.InResponse{}, err
+				}
+				c.logger.Debugf(
+					"Writing pipeline contents to: %s\n",
+					filepath.Join(c.downloadDir, pipelineContentsFilename),
+				)
+				err = files.write(pipelineContentsFilename, outContents)
+				if err != nil {
+					return concourse.InResponse{}, err
+				}
+
+				// out reads config files relative to the directory containing
+				// all inputs, in which this one is named after the resource.
+				entry.ConfigFile = filepath.Join(filepath.Base(c.downloadDir), pipelineContentsFilename)
+			}
+
+			manifest = append(manifest, entry)
+
+			if input.Params.Docs {
+				docsFilename, err := layout.path(teamName, pipelineName, docsExtension)
+				if err != nil {
+					return concourse.InResponse{}, err
+				}
+
+				c.logger.Debugf("Writing pipeline docs to: %s\n", filepath.Join(c.downloadDir, docsFilename))
+				err = files.write(docsFilename, renderDocs(teamName, pipelineName, parsed))
+				if err != nil {
+					return concourse.InResponse{}, err
+				}
+			}
+
+			if input.Params.Graph {
+				err = c.writeGraph(files, layout, teamName, pipelineName, pipelineGraph(parsed))
+				if err != nil {
+					return concourse.InResponse{}, err
+				}
+			}
+
+			if input.Params.CrossPipelineGraph {
+				graphPipelines = append(graphPipelines, graphPipeline{
+					name:     pipelineName,
+					teamName: teamName,
+					config:   parsed,
+				})
+			}
+		}
+	}
+
+	err = c.writeManifest(files, manifest)
+	if err != nil {
+		return concourse.InResponse{}, err
+	}
+
+	if redactor != nil {
+		err = c.writeRedactions(files, redactions)
+		if err != nil {
+			return concourse.InResponse{}, err
 		}
 	}
 
+	if input.Params.Inventory {
+		err = c.writeInventory(files, inventory)
+		if err != nil {
+			return concourse.InResponse{}, err
+		}
+	}
+
+	if input.Params.CrossPipelineGraph {
+		err = c.writeCrossPipelineGraph(files, graphPipelines)
+		if err != nil {
+			return concourse.InResponse{}, err
+		}
+	}
+
+	var pipelineDrift drift
+	if input.Params.ExpectedManifest != "" {
+		pipelineDrift, err = c.detectDrift(input.Params, input.Source.Teams, liveConfigs, redactor)
+		if err != nil {
+			return concourse.InResponse{}, err
+		}
+
+		err = c.writeDrift(files, pipelineDrift)
+		if err != nil {
+			return concourse.InResponse{}, err
+		}
+	}
+
+	var archiveChecksum string
+	if input.Params.Archive {
+		archiveChecksum, err = c.writeArchive(files)
+		if err != nil {
+			return concourse.InResponse{}, err
+		}
+	}
+
+	// Requested pipelines which were not fetched can only be known to be
+	// missing if every pipeline was fetched.
+	checkMissing := len(input.Params.Teams) == 0 && len(input.Params.Pipelines) == 0
+
+	mismatched := mismatchedPipelines(input.Version, liveVersions, checkMissing)
+
+	metadata := buildMetadata(input.Source.Target, manifest, mismatched)
+
+	if redactor != nil {
+		redacted := 0
+		for _, r := range redactions {
+			redacted += len(r.Paths)
+		}
+
+		metadata = append(metadata, concourse.Metadata{
+			Name:  "redacted_values",
+			Value: strconv.Itoa(redacted),
+		})
+	}
+
+	if input.Params.Archive {
+		metadata = append(metadata, concourse.Metadata{
+			Name:  "archive_sha256",
+			Value: archiveChecksum,
+		})
+	}
+
+	if input.Params.ExpectedManifest != "" {
+		metadata = append(metadata,
+			concourse.Metadata{Name: "drift_changed", Value: strconv.Itoa(len(pipelineDrift.Changed))},
+			concourse.Metadata{Name: "drift_missing", Value: strconv.Itoa(len(pipelineDrift.Missing))},
+			concourse.Metadata{Name: "drift_undeclared", Value: strconv.Itoa(len(pipelineDrift.Undeclared))},
+		)
+
+		if pipelineDrift.count() > 0 {
+			if input.Params.FailOnDrift {
+				return concourse.InResponse{}, fmt.Errorf("pipelines have drifted from the expected manifest: %s", pipelineDrift)
+			}
+
+			fmt.Fprintf(os.Stderr, "WARNING: pipelines have drifted from the expected manifest: %s\n", pipelineDrift)
+		}
+	}
+
+	if len(mismatched) > 0 {
+		names := strings.Join(mismatched, ", ")
+
+		if input.Params.Strict {
+			return concourse.InResponse{}, fmt.Errorf("pipelines do not match requested version: %s", names)
+		}
+
+		c.logger.Debugf("Pipelines do not match requested version: %s\n", names)
+		fmt.Fprintf(os.Stderr, "WARNING: pipelines do not match requested version: %s\n", names)
+
+		metadata = append(metadata, concourse.Metadata{
+			Name:  "version_mismatch",
+			Value: names,
+		})
+	}
+
 	response := concourse.InResponse{
 		Version:  input.Version,
-		Metadata: []concourse.Metadata{},
+		Metadata: metadata,
 	}
 
 	return response, nil
 }
 
-type pipelineWithContent struct {
-	name     string
-	contents []byte
+// mismatchedPipelines returns the sorted names of the pipelines in the
+// requested version whose live config has changed or, if checkMissing is
+// true, no longer exists. A pipeline whose name is shared by several teams
+// matches if the live config of any of them does, as check records the
+// version of only one.
+func mismatchedPipelines(requested concourse.Version, live map[string][]string, checkMissing bool) []string {
+	var mismatched []string
+	for name, version := range requested {
+		liveVersions, found := live[name]
+		if !found && !checkMissing {
+			continue
+		}
+
+		matched := false
+		for _, liveVersion := range liveVersions {
+			if liveVersion == version {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			mismatched = append(mismatched, name)
+		}
+	}
+
+	sort.Strings(mismatched)
+	return mismatched
+}
+
+// manifestPipeline is an entry of the manifest written alongside the pipeline
+// configs, in the format read from pipelines_file by out.
+type manifestPipeline struct {
+	Name       string `yaml:"name"`
+	TeamName   string `yaml:"team"`
+	ConfigFile string `yaml:"config_file,omitempty"`
+	ConfigDir  string `yaml:"config_dir,omitempty"`
+	Unpaused   bool   `yaml:"unpaused"`
+	Exposed    bool   `yaml:"exposed"`
+}
+
+// writeManifest writes the manifest sorted by team, so that it is stable
+// regardless of the order in which teams were processed.
+func (c *Command) writeManifest(files *downloadFiles, manifest []manifestPipeline) error {
+	sort.SliceStable(manifest, func(i, j int) bool {
+		return manifest[i].TeamName < manifest[j].TeamName
+	})
+
+	b, err := yaml.Marshal(struct {
+		Pipelines []manifestPipeline `yaml:"pipelines"`
+	}{
+		Pipelines: manifest,
+	})
+	if err != nil {
+		// Untested as it is too hard to force yaml.Marshal to error
+		return err
+	}
+
+	c.logger.Debugf("Writing pipelines manifest to: %s\n", filepath.Join(c.downloadDir, manifestFilename))
+
+	return files.write(manifestFilename, b)
 }
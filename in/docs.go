@@ -0,0 +1,163 @@
+package in
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/concourse/concourse-pipeline-resource/config"
+)
+
+const docsExtension = "md"
+
+// renderDocs describes a pipeline in Markdown: its groups, its jobs with
+// their inputs, outputs and tasks, and its resources and resource types.
+func renderDocs(teamName string, pipelineName string, p config.Pipeline) []byte {
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "# %s/%s\n", escapeMarkdown(teamName), escapeMarkdown(pipelineName))
+
+	if len(p.Groups) > 0 {
+		fmt.Fprintf(buf, "\n## Groups\n\n")
+		fmt.Fprintf(buf, "| Group | Jobs | Resources |\n")
+		fmt.Fprintf(buf, "| --- | --- | --- |\n")
+		for _, g := range p.Groups {
+			fmt.Fprintf(buf, "| %s | %s | %s |\n", escapeMarkdown(g.Name), codeList(g.Jobs), codeList(g.Resources))
+		}
+	}
+
+	if len(p.Jobs) > 0 {
+		fmt.Fprintf(buf, "\n## Jobs\n")
+		for _, j := range p.Jobs {
+			writeJobDocs(buf, j)
+		}
+	}
+
+	if len(p.Resources) > 0 {
+		usedBy := resourceUsage(p.Jobs)
+
+		fmt.Fprintf(buf, "\n## Resources\n\n")
+		fmt.Fprintf(buf, "| Resource | Type | Used by |\n")
+		fmt.Fprintf(buf, "| --- | --- | --- |\n")
+		for _, r := range p.Resources {
+			fmt.Fprintf(buf, "| %s | %s | %s |\n", code(r.Name), code(r.Type), codeList(usedBy[r.Name]))
+		}
+	}
+
+	if len(p.ResourceTypes) > 0 {
+		fmt.Fprintf(buf, "\n## Resource types\n\n")
+		fmt.Fprintf(buf, "| Resource type | Type | Image |\n")
+		fmt.Fprintf(buf, "| --- | --- | --- |\n")
+		for _, rt := range p.ResourceTypes {
+			fmt.Fprintf(buf, "| %s | %s | %s |\n", code(rt.Name), code(rt.Type), code(config.ImageReference(rt.Source)))
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func writeJobDocs(buf *bytes.Buffer, j config.Job) {
+	fmt.Fprintf(buf, "\n### %s\n", escapeMarkdown(j.Name))
+
+	var inputs, outputs, tasks []string
+	for _, s := range j.Steps() {
+		switch {
+		case s.Get != "":
+			input := code(s.ResourceName())
+			var details []string
+			if s.Trigger {
+				details = append(details, "triggers the job")
+			}
+			if len(s.Passed) > 0 {
+				details = append(details, fmt.Sprintf("passed %s", codeList(s.Passed)))
+			}
+			if len(details) > 0 {
+				input = fmt.Sprintf("%s (%s)", input, strings.Join(details, "; "))
+			}
+			inputs = append(inputs, input)
+		case s.Put != "":
+			outputs = append(outputs, code(s.ResourceName()))
+		case s.Task != "":
+			tasks = append(tasks, code(s.Task))
+		}
+	}
+
+	if j.Serial {
+		fmt.Fprintf(buf, "\nBuilds run serially.\n")
+	}
+
+	if len(inputs) == 0 && len(outputs) == 0 && len(tasks) == 0 {
+		fmt.Fprintf(buf, "\nNo steps.\n")
+		return
+	}
+
+	writeDocsList(buf, "Inputs", inputs)
+	writeDocsList(buf, "Tasks", tasks)
+	writeDocsList(buf, "Outputs", outputs)
+}
+
+func writeDocsList(buf *bytes.Buffer, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+
+	fmt.Fprintf(buf, "\n%s:\n\n", title)
+	for _, item := range items {
+		fmt.Fprintf(buf, "* %s\n", item)
+	}
+}
+
+// resourceUsage returns the names of the jobs which get or put each resource.
+func resourceUsage(jobs []config.Job) map[string][]string {
+	usedBy := make(map[string][]string)
+	for _, j := range jobs {
+		for _, s := range j.Steps() {
+			if s.Get == "" && s.Put == "" {
+				continue
+			}
+
+			name := s.ResourceName()
+			if !stringContains(usedBy[name], j.Name) {
+				usedBy[name] = append(usedBy[name], j.Name)
+			}
+		}
+	}
+
+	return usedBy
+}
+
+func code(s string) string {
+	if s == "" {
+		return ""
+	}
+	return fmt.Sprintf("`%s`", strings.Replace(s, "|", `\|`, -1))
+}
+
+func codeList(items []string) string {
+	var coded []string
+	for _, item := range items {
+		coded = append(coded, code(item))
+	}
+	return strings.Join(coded, ", ")
+}
+
+func escapeMarkdown(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		"`", "\\`",
+		"*", `\*`,
+		"_", `\_`,
+		"|", `\|`,
+		"#", `\#`,
+	)
+	return replacer.Replace(s)
+}
+
+func stringContains(slice []string, str string) bool {
+	for _, s := range slice {
+		if s == str {
+			return true
+		}
+	}
+
+	return false
+}
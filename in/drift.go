@@ -0,0 +1,244 @@
+package in
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/concourse/concourse-pipeline-resource/concourse"
+	"github.com/concourse/concourse-pipeline-resource/config"
+	"github.com/concourse/concourse-pipeline-resource/diff"
+	"github.com/concourse/concourse-pipeline-resource/filereader"
+	"github.com/concourse/concourse-pipeline-resource/redact"
+	"github.com/concourse/concourse-pipeline-resource/varsfile"
+)
+
+const driftFilename = "drift.json"
+
+type pipelineKey struct {
+	teamName string
+	name     string
+}
+
+func (k pipelineKey) String() string {
+	return fmt.Sprintf("%s/%s", k.teamName, k.name)
+}
+
+type driftedPipeline struct {
+	Name     string `json:"name"`
+	TeamName string `json:"team"`
+	Diff     string `json:"diff,omitempty"`
+}
+
+// drift lists the pipelines whose live configs differ from those declared in
+// the expected manifest, those which are declared but do not exist, and those
+// which exist but are not declared.
+type drift struct {
+	Changed    []driftedPipeline `json:"changed"`
+	Missing    []driftedPipeline `json:"missing"`
+	Undeclared []driftedPipeline `json:"undeclared"`
+}
+
+func (d drift) count() int {
+	return len(d.Changed) + len(d.Missing) + len(d.Undeclared)
+}
+
+func (d drift) String() string {
+	var parts []string
+	for _, category := range []struct {
+		name      string
+		pipelines []driftedPipeline
+	}{
+		{"changed", d.Changed},
+		{"missing", d.Missing},
+		{"undeclared", d.Undeclared},
+	} {
+		if len(category.pipelines) == 0 {
+			continue
+		}
+
+		var names []string
+		for _, p := range category.pipelines {
+			names = append(names, fmt.Sprintf("%s/%s", p.TeamName, p.Name))
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", category.name, strings.Join(names, ", ")))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// detectDrift renders the selected pipelines declared in the expected
+// manifest and compares them with the live configs, ignoring the order of
+// keys. The manifest, and the files it refers to, are relative to the
+// directory containing the download directory, as for pipelines_file in out.
+// The vars shared by the pipelines of a team in source, and the vars_from_env
+// of each pipeline, are applied as in out.
+// If redactor is not nil, it is applied to the diffs of changed pipelines.
+func (c *Command) detectDrift(
+	params concourse.InParams,
+	teams []concourse.Team,
+	live map[pipelineKey][]byte,
+	redactor *redact.Redactor,
+) (drift, error) {
+	sourcesDir := filepath.Dir(c.downloadDir)
+
+	declared, err := filereader.PipelinesFromFile(params.ExpectedManifest, sourcesDir)
+	if err != nil {
+		return drift{}, err
+	}
+
+	mergedDir, err := ioutil.TempDir("", "concourse-pipeline-resource-config-dir")
+	if err != nil {
+		return drift{}, err
+	}
+	defer os.RemoveAll(mergedDir)
+
+	d := drift{
+		Changed:    []driftedPipeline{},
+		Missing:    []driftedPipeline{},
+		Undeclared: []driftedPipeline{},
+	}
+	seen := make(map[pipelineKey]bool)
+
+	for i, p := range declared {
+		key := pipelineKey{teamName: p.TeamName, name: p.Name}
+		if p.ValidateOnly || !selected(params.Teams, key.teamName) || !pipelineSelected(params.Pipelines, key.teamName, key.name) {
+			continue
+		}
+		seen[key] = true
+
+		liveConfig, found := live[key]
+		if !found {
+			d.Missing = append(d.Missing, driftedPipeline{Name: key.name, TeamName: key.teamName})
+			continue
+		}
+
+		configFilepath := filepath.Join(sourcesDir, p.ConfigFile)
+		if p.ConfigDir != "" {
+			merged, err := config.Merge(filepath.Join(sourcesDir, p.ConfigDir))
+			if err != nil {
+				return drift{}, err
+			}
+
+			configFilepath = filepath.Join(mergedDir, fmt.Sprintf("%d.yml", i))
+			err = ioutil.WriteFile(configFilepath, merged, fileMode)
+			if err != nil {
+				// Untested as it is too hard to force ioutil.WriteFile to error
+				return drift{}, err
+			}
+		}
+
+		var sharedVars []concourse.SharedVars
+		for _, team := range teams {
+			if team.Name == p.TeamName {
+				sharedVars = append(sharedVars, concourse.SharedVars{VarsFiles: team.VarsFiles, Vars: team.Vars})
+			}
+		}
+
+		varsFilepaths, err := varsfile.Filepaths(sharedVars, p.VarsFiles, sourcesDir, mergedDir)
+		if err != nil {
+			return drift{}, err
+		}
+
+		envVars, err := varsfile.FromEnv(p.VarsFromEnv)
+		if err != nil {
+			return drift{}, fmt.Errorf("pipeline '%s': %s", key, err)
+		}
+
+		c.logger.Debugf("Rendering expected pipeline: %s\n", key)
+		expectedConfig, err := c.flyCommand.RenderPipeline(configFilepath, varsFilepaths, varsfile.Merge(envVars, p.Vars))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "pipeline '%s' failed to render; output:\n\n%s\n", key, string(expectedConfig))
+			return drift{}, err
+		}
+
+		expected, err := comparableConfig(expectedConfig, nil)
+		if err != nil {
+			return drift{}, err
+		}
+
+		actual, err := comparableConfig(liveConfig, nil)
+		if err != nil {
+			return drift{}, err
+		}
+
+		if string(expected) == string(actual) {
+			continue
+		}
+
+		if redactor != nil {
+			expected, err = comparableConfig(expectedConfig, redactor)
+			if err != nil {
+				return drift{}, err
+			}
+
+			actual, err = comparableConfig(liveConfig, redactor)
+			if err != nil {
+				return drift{}, err
+			}
+		}
+
+		d.Changed = append(d.Changed, driftedPipeline{
+			Name:     key.name,
+			TeamName: key.teamName,
+			Diff: diff.Unified(
+				fmt.Sprintf("expected/%s", key),
+				fmt.Sprintf("live/%s", key),
+				expected,
+				actual,
+			),
+		})
+	}
+
+	for key := range live {
+		if !seen[key] {
+			d.Undeclared = append(d.Undeclared, driftedPipeline{Name: key.name, TeamName: key.teamName})
+		}
+	}
+
+	for _, pipelines := range [][]driftedPipeline{d.Changed, d.Missing, d.Undeclared} {
+		sortDriftedPipelines(pipelines)
+	}
+
+	return d, nil
+}
+
+// comparableConfig returns the config normalized, and redacted if redactor
+// is not nil, so that configs which differ only in formatting are equal.
+func comparableConfig(contents []byte, redactor *redact.Redactor) ([]byte, error) {
+	doc, err := config.Parse(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	if redactor != nil {
+		redactor.Redact(doc)
+	}
+
+	return config.Marshal(config.Normalize(doc), false)
+}
+
+func sortDriftedPipelines(pipelines []driftedPipeline) {
+	sort.SliceStable(pipelines, func(i, j int) bool {
+		if pipelines[i].TeamName != pipelines[j].TeamName {
+			return pipelines[i].TeamName < pipelines[j].TeamName
+		}
+		return pipelines[i].Name < pipelines[j].Name
+	})
+}
+
+func (c *Command) writeDrift(files *downloadFiles, d drift) error {
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		// Untested as it is too hard to force json.MarshalIndent to error
+		return err
+	}
+
+	c.logger.Debugf("Writing drift report to: %s\n", filepath.Join(c.downloadDir, driftFilename))
+
+	return files.write(driftFilename, b)
+}
@@ -0,0 +1,35 @@
+package in
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// downloadFiles writes files to the download directory, recording the path
+// of each so that they can later be archived.
+type downloadFiles struct {
+	dir   string
+	paths []string
+}
+
+// write writes contents to path, relative to the download directory,
+// creating any parent directories.
+func (f *downloadFiles) write(path string, contents []byte) error {
+	fullPath := filepath.Join(f.dir, path)
+
+	err := os.MkdirAll(filepath.Dir(fullPath), dirMode)
+	// Untested as it is too hard to force os.MkdirAll to error
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(fullPath, contents, fileMode)
+	// Untested as it is too hard to force ioutil.WriteFile to error
+	if err != nil {
+		return err
+	}
+
+	f.paths = append(f.paths, path)
+	return nil
+}
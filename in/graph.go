@@ -0,0 +1,301 @@
+package in
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/concourse/concourse-pipeline-resource/config"
+)
+
+const (
+	dotExtension     = "dot"
+	mermaidExtension = "mmd"
+
+	crossPipelineGraphName       = "cross-pipeline"
+	crossPipelineDotFilename     = crossPipelineGraphName + "." + dotExtension
+	crossPipelineMermaidFilename = crossPipelineGraphName + "." + mermaidExtension
+
+	nodeJob      = "job"
+	nodeResource = "resource"
+	nodePipeline = "pipeline"
+)
+
+type graphNode struct {
+	id    string
+	label string
+	kind  string
+}
+
+// graphEdge is dashed if it does not trigger builds, such as a get without
+// trigger: true.
+type graphEdge struct {
+	from   string
+	to     string
+	dashed bool
+}
+
+// graph is a directed graph which is rendered as Graphviz DOT or Mermaid.
+// Nodes and edges are rendered in the order in which they were added.
+type graph struct {
+	nodes []graphNode
+	edges []graphEdge
+
+	nodeIDs map[string]bool
+	edgeIDs map[string]int
+}
+
+func newGraph() *graph {
+	return &graph{
+		nodeIDs: make(map[string]bool),
+		edgeIDs: make(map[string]int),
+	}
+}
+
+func (g *graph) addNode(kind string, name string, label string) string {
+	id := fmt.Sprintf("%s:%s", kind, name)
+	if !g.nodeIDs[id] {
+		g.nodeIDs[id] = true
+		g.nodes = append(g.nodes, graphNode{id: id, label: label, kind: kind})
+	}
+	return id
+}
+
+// addEdge adds an edge between two nodes; adding the same edge again only
+// makes it solid if it was dashed.
+func (g *graph) addEdge(from string, to string, dashed bool) {
+	id := from + "\x00" + to
+	if i, found := g.edgeIDs[id]; found {
+		g.edges[i].dashed = g.edges[i].dashed && dashed
+		return
+	}
+
+	g.edgeIDs[id] = len(g.edges)
+	g.edges = append(g.edges, graphEdge{from: from, to: to, dashed: dashed})
+}
+
+// pipelineGraph returns the graph of jobs and resources of a pipeline: an
+// edge from each resource to the jobs which get it, and from each job to the
+// resources it puts. A passed constraint adds an edge from each of the
+// passed jobs to the resource, so that jobs are linked through resources.
+func pipelineGraph(p config.Pipeline) *graph {
+	g := newGraph()
+
+	for _, j := range p.Jobs {
+		g.addNode(nodeJob, j.Name, j.Name)
+	}
+
+	for _, r := range p.Resources {
+		g.addNode(nodeResource, r.Name, r.Name)
+	}
+
+	for _, j := range p.Jobs {
+		jobID := g.addNode(nodeJob, j.Name, j.Name)
+
+		for _, s := range j.Steps() {
+			switch {
+			case s.Get != "":
+				resourceID := g.addNode(nodeResource, s.ResourceName(), s.ResourceName())
+				for _, passed := range s.Passed {
+					g.addEdge(g.addNode(nodeJob, passed, passed), resourceID, false)
+				}
+				g.addEdge(resourceID, jobID, !s.Trigger)
+			case s.Put != "":
+				g.addEdge(jobID, g.addNode(nodeResource, s.ResourceName(), s.ResourceName()), false)
+			}
+		}
+	}
+
+	return g
+}
+
+type graphPipeline struct {
+	name     string
+	teamName string
+	config   config.Pipeline
+}
+
+// crossPipelineGraph returns the graph of the resources shared between
+// pipelines: resources of the same type and source in two or more pipelines,
+// with an edge from each pipeline which puts the resource and to each
+// pipeline which gets it.
+func crossPipelineGraph(pipelines []graphPipeline) *graph {
+	type usage struct {
+		pipeline string
+		get      bool
+		put      bool
+	}
+
+	var keys []string
+	names := make(map[string][]string)
+	usages := make(map[string][]usage)
+
+	for _, p := range pipelines {
+		pipelineName := fmt.Sprintf("%s/%s", p.teamName, p.name)
+
+		gets := make(map[string]bool)
+		puts := make(map[string]bool)
+		for _, j := range p.config.Jobs {
+			for _, s := range j.Steps() {
+				if s.Get != "" {
+					gets[s.ResourceName()] = true
+				}
+				if s.Put != "" {
+					puts[s.ResourceName()] = true
+				}
+			}
+		}
+
+		for _, r := range p.config.Resources {
+			// fmt prints maps with sorted keys, so equal sources have equal keys.
+			key := fmt.Sprintf("%s %v", r.Type, r.Source)
+			if _, found := usages[key]; !found {
+				keys = append(keys, key)
+			}
+
+			if !stringContains(names[key], r.Name) {
+				names[key] = append(names[key], r.Name)
+			}
+
+			usages[key] = append(usages[key], usage{
+				pipeline: pipelineName,
+				get:      gets[r.Name],
+				put:      puts[r.Name],
+			})
+		}
+	}
+
+	g := newGraph()
+
+	for _, p := range pipelines {
+		name := fmt.Sprintf("%s/%s", p.teamName, p.name)
+		g.addNode(nodePipeline, name, name)
+	}
+
+	for i, key := range keys {
+		if len(usages[key]) < 2 {
+			continue
+		}
+
+		sort.Strings(names[key])
+		resourceID := g.addNode(nodeResource, fmt.Sprintf("%d", i), strings.Join(names[key], ", "))
+
+		for _, u := range usages[key] {
+			pipelineID := g.addNode(nodePipeline, u.pipeline, u.pipeline)
+			if u.put {
+				g.addEdge(pipelineID, resourceID, false)
+			}
+			if u.get || !u.put {
+				// Resources which are only declared are still shared.
+				g.addEdge(resourceID, pipelineID, !u.get)
+			}
+		}
+	}
+
+	return g
+}
+
+func (c *Command) writeGraph(files *downloadFiles, l *layout, teamName string, pipelineName string, g *graph) error {
+	dotFilename, err := l.path(teamName, pipelineName, dotExtension)
+	if err != nil {
+		return err
+	}
+
+	c.logger.Debugf("Writing pipeline graph to: %s\n", filepath.Join(c.downloadDir, dotFilename))
+	err = files.write(dotFilename, g.dot(fmt.Sprintf("%s/%s", teamName, pipelineName)))
+	if err != nil {
+		return err
+	}
+
+	mermaidFilename, err := l.path(teamName, pipelineName, mermaidExtension)
+	if err != nil {
+		return err
+	}
+
+	return files.write(mermaidFilename, g.mermaid())
+}
+
+// writeCrossPipelineGraph writes the graph of shared resources, ordered by
+// team and pipeline so that it is stable.
+func (c *Command) writeCrossPipelineGraph(files *downloadFiles, pipelines []graphPipeline) error {
+	sort.SliceStable(pipelines, func(i, j int) bool {
+		if pipelines[i].teamName != pipelines[j].teamName {
+			return pipelines[i].teamName < pipelines[j].teamName
+		}
+		return pipelines[i].name < pipelines[j].name
+	})
+
+	g := crossPipelineGraph(pipelines)
+
+	c.logger.Debugf("Writing cross-pipeline graph to: %s\n", filepath.Join(c.downloadDir, crossPipelineDotFilename))
+	err := files.write(crossPipelineDotFilename, g.dot(crossPipelineGraphName))
+	if err != nil {
+		return err
+	}
+
+	return files.write(crossPipelineMermaidFilename, g.mermaid())
+}
+
+func (g *graph) dot(name string) []byte {
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "digraph %s {\n", dotQuote(name))
+	fmt.Fprintf(buf, "  rankdir=LR;\n")
+
+	for _, n := range g.nodes {
+		shape := "box"
+		if n.kind == nodeResource {
+			shape = "ellipse"
+		}
+		fmt.Fprintf(buf, "  %s [label=%s, shape=%s];\n", dotQuote(n.id), dotQuote(n.label), shape)
+	}
+
+	for _, e := range g.edges {
+		style := ""
+		if e.dashed {
+			style = " [style=dashed]"
+		}
+		fmt.Fprintf(buf, "  %s -> %s%s;\n", dotQuote(e.from), dotQuote(e.to), style)
+	}
+
+	fmt.Fprintf(buf, "}\n")
+	return buf.Bytes()
+}
+
+// mermaid renders the graph as a Mermaid flowchart. Node ids are replaced
+// with sequential ones, as Mermaid ids may not contain arbitrary characters.
+func (g *graph) mermaid() []byte {
+	ids := make(map[string]string)
+
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "flowchart LR\n")
+
+	for i, n := range g.nodes {
+		ids[n.id] = fmt.Sprintf("n%d", i)
+
+		if n.kind == nodeResource {
+			fmt.Fprintf(buf, "  %s([%s])\n", ids[n.id], mermaidQuote(n.label))
+		} else {
+			fmt.Fprintf(buf, "  %s[%s]\n", ids[n.id], mermaidQuote(n.label))
+		}
+	}
+
+	for _, e := range g.edges {
+		arrow := "-->"
+		if e.dashed {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(buf, "  %s %s %s\n", ids[e.from], arrow, ids[e.to])
+	}
+
+	return buf.Bytes()
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s) + `"`
+}
@@ -0,0 +1,134 @@
+package in
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+
+	"github.com/concourse/concourse-pipeline-resource/config"
+)
+
+// inventoryPipeline lists the resource types, resources, jobs, task images
+// and groups of a pipeline, for auditing pipelines across teams.
+type inventoryPipeline struct {
+	Name          string                  `json:"name"`
+	TeamName      string                  `json:"team"`
+	ResourceTypes []inventoryResourceType `json:"resource_types"`
+	Resources     []inventoryResource     `json:"resources"`
+	Jobs          []string                `json:"jobs"`
+	Tasks         []inventoryTask         `json:"tasks"`
+	Groups        []inventoryGroup        `json:"groups"`
+}
+
+type inventoryResourceType struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Image string `json:"image,omitempty"`
+}
+
+type inventoryResource struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// inventoryTask records where a task's image comes from: an image_resource
+// in its inline config, an artifact given by image, or a config file whose
+// image is only known at runtime.
+type inventoryTask struct {
+	Job           string `json:"job"`
+	Name          string `json:"name"`
+	Image         string `json:"image,omitempty"`
+	ImageType     string `json:"image_type,omitempty"`
+	ImageArtifact string `json:"image_artifact,omitempty"`
+	File          string `json:"file,omitempty"`
+}
+
+type inventoryGroup struct {
+	Name      string   `json:"name"`
+	Jobs      []string `json:"jobs"`
+	Resources []string `json:"resources,omitempty"`
+}
+
+func newInventoryPipeline(teamName string, pipelineName string, p config.Pipeline) inventoryPipeline {
+	i := inventoryPipeline{
+		Name:          pipelineName,
+		TeamName:      teamName,
+		ResourceTypes: []inventoryResourceType{},
+		Resources:     []inventoryResource{},
+		Jobs:          []string{},
+		Tasks:         []inventoryTask{},
+		Groups:        []inventoryGroup{},
+	}
+
+	for _, rt := range p.ResourceTypes {
+		i.ResourceTypes = append(i.ResourceTypes, inventoryResourceType{
+			Name:  rt.Name,
+			Type:  rt.Type,
+			Image: config.ImageReference(rt.Source),
+		})
+	}
+
+	for _, r := range p.Resources {
+		i.Resources = append(i.Resources, inventoryResource{
+			Name: r.Name,
+			Type: r.Type,
+		})
+	}
+
+	for _, j := range p.Jobs {
+		i.Jobs = append(i.Jobs, j.Name)
+
+		for _, s := range j.Steps() {
+			if s.Task == "" {
+				continue
+			}
+
+			task := inventoryTask{
+				Job:           j.Name,
+				Name:          s.Task,
+				ImageArtifact: s.Image,
+				File:          s.File,
+			}
+
+			if s.Config != nil && s.Config.ImageResource != nil {
+				task.Image = config.ImageReference(s.Config.ImageResource.Source)
+				task.ImageType = s.Config.ImageResource.Type
+			}
+
+			i.Tasks = append(i.Tasks, task)
+		}
+	}
+
+	for _, g := range p.Groups {
+		i.Groups = append(i.Groups, inventoryGroup{
+			Name:      g.Name,
+			Jobs:      g.Jobs,
+			Resources: g.Resources,
+		})
+	}
+
+	return i
+}
+
+func (c *Command) writeInventory(files *downloadFiles, inventory []inventoryPipeline) error {
+	sort.SliceStable(inventory, func(i, j int) bool {
+		if inventory[i].TeamName != inventory[j].TeamName {
+			return inventory[i].TeamName < inventory[j].TeamName
+		}
+		return inventory[i].Name < inventory[j].Name
+	})
+
+	b, err := json.MarshalIndent(struct {
+		Pipelines []inventoryPipeline `json:"pipelines"`
+	}{
+		Pipelines: inventory,
+	}, "", "  ")
+	if err != nil {
+		// Untested as it is too hard to force json.MarshalIndent to error
+		return err
+	}
+
+	c.logger.Debugf("Writing inventory to: %s\n", filepath.Join(c.downloadDir, inventoryFilename))
+
+	return files.write(inventoryFilename, b)
+}
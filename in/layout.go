@@ -0,0 +1,172 @@
+package in
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const (
+	layoutFlat   = "flat"
+	layoutNested = "nested"
+
+	fileMode = 0644
+	dirMode  = 0755
+)
+
+// layout determines the path, relative to the download directory, at which
+// each pipeline config is written.
+type layout struct {
+	name     string
+	template *template.Template
+
+	// paths records the pipeline written to each path, so that two pipelines
+	// are never written to the same file.
+	paths map[string]string
+
+	// flatNames records the name of each pipeline in the flat layout, and
+	// flatPipelines the pipeline of each name, so that every file of a
+	// pipeline has the same name.
+	flatNames     map[string]string
+	flatPipelines map[string]string
+}
+
+type layoutNames struct {
+	Team     string
+	Pipeline string
+}
+
+func newLayout(name string) (*layout, error) {
+	l := &layout{
+		name: name,
+		paths: map[string]string{
+			manifestFilename:             "the pipelines manifest",
+			redactionsFilename:           "the redactions report",
+			inventoryFilename:            "the inventory",
+			driftFilename:                "the drift report",
+			crossPipelineDotFilename:     "the cross-pipeline graph",
+			crossPipelineMermaidFilename: "the cross-pipeline graph",
+			archiveFilename:              "the pipelines archive",
+			archiveChecksumFilename:      "the pipelines archive checksum",
+		},
+		flatNames:     map[string]string{},
+		flatPipelines: map[string]string{},
+	}
+
+	switch name {
+	case "", layoutFlat:
+		l.name = layoutFlat
+	case layoutNested:
+	default:
+		tmpl, err := template.New("layout").Option("missingkey=error").Parse(name)
+		if err != nil {
+			return nil, err
+		}
+		l.template = tmpl
+	}
+
+	return l, nil
+}
+
+// path returns the path for the config of the given pipeline, or for the
+// directory of its split config if extension is empty, with names escaped so
+// that no two pipelines share a path in the flat or nested layouts. An error
+// is returned if the path would collide with that of a previous pipeline,
+// which is only possible with a custom template.
+func (l *layout) path(teamName string, pipelineName string, extension string) (string, error) {
+	var p string
+
+	switch l.name {
+	case layoutFlat:
+		p = l.flatName(teamName, pipelineName)
+	case layoutNested:
+		p = filepath.Join(escapeName(teamName, ""), escapeName(pipelineName, ""))
+	default:
+		buf := bytes.NewBuffer(nil)
+		err := l.template.Execute(buf, layoutNames{
+			Team:     escapeName(teamName, ""),
+			Pipeline: escapeName(pipelineName, ""),
+		})
+		if err != nil {
+			return "", err
+		}
+
+		p = filepath.Clean(buf.String())
+		if p == "." || filepath.IsAbs(p) || p == ".." || strings.HasPrefix(p, ".."+string(filepath.Separator)) {
+			return "", fmt.Errorf(
+				"layout must produce a path within the download directory; got '%s' for pipeline '%s/%s'",
+				buf.String(),
+				teamName,
+				pipelineName,
+			)
+		}
+	}
+
+	p = withExtension(p, extension)
+
+	pipeline := fmt.Sprintf("%s/%s", teamName, pipelineName)
+	if existing, found := l.paths[p]; found {
+		return "", fmt.Errorf(
+			"pipeline '%s' and %s would both be written to '%s'",
+			pipeline,
+			existing,
+			p,
+		)
+	}
+	l.paths[p] = fmt.Sprintf("pipeline '%s'", pipeline)
+
+	return p, nil
+}
+
+// flatName returns the name of the files of a pipeline in the flat layout.
+// Names are kept as they were before layouts were introduced, unless they
+// are that of a previous pipeline, e.g. main-x/y and main/x-y, in which case
+// dashes are also escaped. Names with escaped dashes are never those of
+// another pipeline, as the first dash always separates the team from the
+// pipeline, and % is always escaped.
+func (l *layout) flatName(teamName string, pipelineName string) string {
+	pipeline := fmt.Sprintf("%s/%s", teamName, pipelineName)
+	if name, found := l.flatNames[pipeline]; found {
+		return name
+	}
+
+	name := fmt.Sprintf("%s-%s", escapeName(teamName, ""), escapeName(pipelineName, ""))
+	if _, found := l.flatPipelines[name]; found {
+		name = fmt.Sprintf("%s-%s", escapeName(teamName, "-"), escapeName(pipelineName, "-"))
+	}
+
+	l.flatNames[pipeline] = name
+	l.flatPipelines[name] = pipeline
+
+	return name
+}
+
+func withExtension(p string, extension string) string {
+	if extension == "" {
+		return p
+	}
+
+	return fmt.Sprintf("%s.%s", p, extension)
+}
+
+// escapeName percent-encodes the characters of a team or pipeline name which
+// are not safe in a single path component, along with any of extra.
+func escapeName(name string, extra string) string {
+	if name == "." || name == ".." {
+		return strings.Replace(name, ".", "%2E", -1)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c == '%' || c == '/' || c == '\\' || c < 0x20 || strings.IndexByte(extra, c) >= 0 {
+			fmt.Fprintf(buf, "%%%02X", c)
+			continue
+		}
+		buf.WriteByte(c)
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,63 @@
+package in
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/concourse/concourse-pipeline-resource/concourse"
+)
+
+// buildMetadata summarizes the fetched pipelines for display in the UI. The
+// manifest must already be sorted by team, and the mismatched pipelines by
+// name.
+func buildMetadata(
+	target string,
+	manifest []manifestPipeline,
+	mismatched []string,
+) []concourse.Metadata {
+	m := []concourse.Metadata{
+		{Name: "target", Value: target},
+		{Name: "pipelines", Value: strconv.Itoa(len(manifest))},
+	}
+
+	var teamNames []string
+	teamCounts := make(map[string]int)
+	var paused []string
+
+	for _, p := range manifest {
+		if _, found := teamCounts[p.TeamName]; !found {
+			teamNames = append(teamNames, p.TeamName)
+		}
+		teamCounts[p.TeamName]++
+
+		if !p.Unpaused {
+			paused = append(paused, fmt.Sprintf("%s/%s", p.TeamName, p.Name))
+		}
+	}
+
+	for _, teamName := range teamNames {
+		m = append(m, concourse.Metadata{
+			Name:  fmt.Sprintf("pipelines_in_%s", teamName),
+			Value: strconv.Itoa(teamCounts[teamName]),
+		})
+	}
+
+	if len(paused) > 0 {
+		m = append(m, concourse.Metadata{
+			Name:  "paused_pipelines",
+			Value: strings.Join(paused, ", "),
+		})
+	}
+
+	// Pipelines which are not in the requested version, such as those not set
+	// by the put whose version is requested, are not known to have changed.
+	if len(mismatched) > 0 {
+		m = append(m, concourse.Metadata{
+			Name:  "changed_pipelines",
+			Value: strings.Join(mismatched, ", "),
+		})
+	}
+
+	return m
+}
@@ -0,0 +1,68 @@
+package in
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+
+	"github.com/concourse/concourse-pipeline-resource/config"
+	"github.com/concourse/concourse-pipeline-resource/redact"
+)
+
+// redactedPipeline is an entry of the report of the values redacted from the
+// downloaded pipeline configs.
+type redactedPipeline struct {
+	Name     string   `json:"name"`
+	TeamName string   `json:"team"`
+	Paths    []string `json:"paths"`
+}
+
+// rewriteConfig returns the config, in the same format, with secret values
+// replaced if redactor is not nil and normalized if normalize is true, along
+// with the paths of any redacted values. The config is returned unchanged if
+// it did not need to be rewritten.
+func rewriteConfig(contents []byte, asJSON bool, redactor *redact.Redactor, normalize bool) ([]byte, []string, error) {
+	doc, err := config.Parse(contents)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var paths []string
+	if redactor != nil {
+		paths = redactor.Redact(doc)
+	}
+
+	if !normalize && len(paths) == 0 {
+		return contents, nil, nil
+	}
+
+	if normalize {
+		doc = config.Normalize(doc)
+	}
+
+	rewritten, err := config.Marshal(doc, asJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rewritten, paths, nil
+}
+
+func (c *Command) writeRedactions(files *downloadFiles, redactions []redactedPipeline) error {
+	sort.SliceStable(redactions, func(i, j int) bool {
+		if redactions[i].TeamName != redactions[j].TeamName {
+			return redactions[i].TeamName < redactions[j].TeamName
+		}
+		return redactions[i].Name < redactions[j].Name
+	})
+
+	b, err := json.MarshalIndent(redactions, "", "  ")
+	if err != nil {
+		// Untested as it is too hard to force json.MarshalIndent to error
+		return err
+	}
+
+	c.logger.Debugf("Writing redactions report to: %s\n", filepath.Join(c.downloadDir, redactionsFilename))
+
+	return files.write(redactionsFilename, b)
+}
@@ -0,0 +1,45 @@
+package in
+
+import (
+	"path"
+	"strings"
+)
+
+// selected returns true if the name matches any of the glob patterns, or if
+// there are no patterns.
+func selected(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		// Malformed patterns are rejected by validation, so errors are ignored.
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pipelineSelected returns true if the pipeline matches any of the patterns.
+// Patterns containing a slash are matched against `<team>/<pipeline>`, and
+// all others against the pipeline name alone.
+func pipelineSelected(patterns []string, teamName string, pipelineName string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		name := pipelineName
+		if strings.Contains(pattern, "/") {
+			name = teamName + "/" + pipelineName
+		}
+
+		if selected([]string{pattern}, name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,106 @@
+package in
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+
+	"github.com/concourse/concourse-pipeline-resource/config"
+	"gopkg.in/yaml.v2"
+)
+
+const splitJobsKey = "jobs"
+
+// writeSplit writes each top-level section of a pipeline config to its own
+// file in dir, with each job in a file of its own under jobs/, along with a
+// manifest from which out can merge the sections back together.
+func (c *Command) writeSplit(files *downloadFiles, dir string, doc yaml.MapSlice, extension string, asJSON bool) error {
+	var manifest config.SplitManifest
+	written := map[string]bool{
+		config.SplitManifestFilename: true,
+	}
+
+	write := func(filename string, v interface{}) error {
+		if written[filename] {
+			return fmt.Errorf("pipeline config section would be written twice to '%s'", path.Join(dir, filename))
+		}
+		written[filename] = true
+
+		b, err := config.Marshal(v, asJSON)
+		if err != nil {
+			return err
+		}
+
+		return files.write(filepath.Join(dir, filepath.FromSlash(filename)), b)
+	}
+
+	for _, item := range doc {
+		key := fmt.Sprint(item.Key)
+		section := config.SplitSection{Key: key}
+
+		if jobs, ok := splitJobs(key, item.Value); ok {
+			section.Files = []string{}
+			for _, job := range jobs {
+				filename := fmt.Sprintf("%s/%s.%s", splitJobsKey, escapeName(job.name, ""), extension)
+				err := write(filename, job.config)
+				if err != nil {
+					return err
+				}
+
+				section.Files = append(section.Files, filename)
+			}
+		} else {
+			section.File = fmt.Sprintf("%s.%s", escapeName(key, ""), extension)
+			err := write(section.File, item.Value)
+			if err != nil {
+				return err
+			}
+		}
+
+		manifest.Sections = append(manifest.Sections, section)
+	}
+
+	b, err := yaml.Marshal(manifest)
+	if err != nil {
+		// Untested as it is too hard to force yaml.Marshal to error
+		return err
+	}
+
+	return files.write(filepath.Join(dir, config.SplitManifestFilename), b)
+}
+
+type splitJob struct {
+	name   string
+	config yaml.MapSlice
+}
+
+// splitJobs returns the jobs of the jobs section, or false if it cannot be
+// split because a job is not a mapping with a name.
+func splitJobs(key string, v interface{}) ([]splitJob, bool) {
+	items, ok := v.([]interface{})
+	if key != splitJobsKey || !ok {
+		return nil, false
+	}
+
+	var jobs []splitJob
+	for _, item := range items {
+		job, ok := item.(yaml.MapSlice)
+		if !ok {
+			return nil, false
+		}
+
+		var name string
+		for _, field := range job {
+			if field.Key == "name" {
+				name, _ = field.Value.(string)
+			}
+		}
+		if name == "" {
+			return nil, false
+		}
+
+		jobs = append(jobs, splitJob{name: name, config: job})
+	}
+
+	return jobs, true
+}
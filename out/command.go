@@ -3,13 +3,17 @@ package out
 import (
 	"crypto/md5"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
 
 	"github.com/concourse/concourse-pipeline-resource/concourse"
+	"github.com/concourse/concourse-pipeline-resource/config"
+	"github.com/concourse/concourse-pipeline-resource/diff"
 	"github.com/concourse/concourse-pipeline-resource/fly"
 	"github.com/concourse/concourse-pipeline-resource/logger"
+	"github.com/concourse/concourse-pipeline-resource/varsfile"
 )
 
 const (
@@ -52,17 +56,95 @@ func (c *Command) Run(input concourse.OutRequest) (concourse.OutResponse, error)
 		teams[team.Name] = team
 	}
 
-	pipelines := input.Params.Pipelines
+	sharedEnvVars, err := varsfile.FromEnv(input.Params.VarsFromEnv)
+	if err != nil {
+		return concourse.OutResponse{}, err
+	}
+
+	// The build metadata comes first, followed by the vars shared by every
+	// pipeline, and by the pipelines of a team, all passed as vars files
+	// before those of each pipeline in increasing order of precedence, so
+	// that a pipeline can override them in its own vars files. Vars from the
+	// environment take precedence below the vars they are provided with.
+	sharedParamsVars := varsfile.Merge(sharedEnvVars, input.Params.Vars)
+
+	pipelines := make([]concourse.Pipeline, len(input.Params.Pipelines))
+	sharedVars := make([][]concourse.SharedVars, len(input.Params.Pipelines))
+	for i, p := range input.Params.Pipelines {
+		pipelineEnvVars, err := varsfile.FromEnv(p.VarsFromEnv)
+		if err != nil {
+			return concourse.OutResponse{}, fmt.Errorf("pipeline '%s': %s", p.Name, err)
+		}
+
+		p.Vars = varsfile.Merge(pipelineEnvVars, p.Vars)
+		pipelines[i] = p
+
+		team := teams[p.TeamName]
+		sharedVars[i] = []concourse.SharedVars{
+			{Vars: buildMetadataVars()},
+			{VarsFiles: input.Params.VarsFiles, Vars: sharedParamsVars},
+			{VarsFiles: team.VarsFiles, Vars: team.Vars},
+		}
+	}
 
 	c.logger.Debugf("Input pipelines: %+v\n", pipelines)
 
+	mergedDir, err := ioutil.TempDir("", "concourse-pipeline-resource-config-dir")
+	if err != nil {
+		return concourse.OutResponse{}, err
+	}
+	defer os.RemoveAll(mergedDir)
+
+	varsDir, err := ioutil.TempDir("", "concourse-pipeline-resource-vars-dir")
+	if err != nil {
+		return concourse.OutResponse{}, err
+	}
+	defer os.RemoveAll(varsDir)
+
+	configFilepaths := make([]string, len(pipelines))
+	varsFilepaths := make([][]string, len(pipelines))
+	for i, p := range pipelines {
+		configFilepaths[i], err = c.configFilepath(p, mergedDir, i)
+		if err != nil {
+			return concourse.OutResponse{}, err
+		}
+
+		varsFilepaths[i], err = varsfile.Filepaths(sharedVars[i], p.VarsFiles, c.sourcesDir, varsDir)
+		if err != nil {
+			return concourse.OutResponse{}, err
+		}
+	}
+
+	c.logger.Debugf("Validating pipelines\n")
+	for i, p := range pipelines {
+		validateOutput, err := c.flyCommand.ValidatePipeline(
+			configFilepaths[i],
+			varsFilepaths[i],
+			p.Vars,
+			input.Params.StrictValidation,
+		)
+		c.logger.Debugf("pipeline '%s' validated; output:\n\n%s\n", p.Name, string(validateOutput))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "pipeline '%s' failed validation; output:\n\n%s\n", p.Name, string(validateOutput))
+			return concourse.OutResponse{}, err
+		}
+	}
+	c.logger.Debugf("Validating pipelines complete\n")
+
+	var jobChanges, resourceChanges diff.Changes
+
 	c.logger.Debugf("Setting pipelines\n")
-	for _, p := range pipelines {
+	for i, p := range pipelines {
 		team, found := teams[p.TeamName]
 		if !found {
 			return concourse.OutResponse{}, fmt.Errorf("team (%s) configuration not found for pipeline (%s)", p.TeamName, p.Name)
 		}
 
+		if validateOnly(input.Params, p) {
+			c.logger.Debugf("Not setting validate-only pipeline: %s\n", p.Name)
+			continue
+		}
+
 		c.logger.Debugf("Performing login\n")
 		_, err := c.flyCommand.Login(
 			input.Source.Target,
@@ -77,22 +159,61 @@ func (c *Command) Run(input concourse.OutRequest) (concourse.OutResponse, error)
 
 		c.logger.Debugf("Login successful\n")
 
-		configFilepath := filepath.Join(c.sourcesDir, p.ConfigFile)
+		existingPipelines, err := c.flyCommand.Pipelines()
+		if err != nil {
+			return concourse.OutResponse{}, err
+		}
+
+		if p.RenamedFrom != "" {
+			renamed, err := c.renamePipeline(existingPipelines, p.RenamedFrom, p.Name)
+			if err != nil {
+				return concourse.OutResponse{}, err
+			}
 
-		var varsFilepaths []string
-		for _, v := range p.VarsFiles {
-			varFilepath := filepath.Join(c.sourcesDir, v)
-			varsFilepaths = append(varsFilepaths, varFilepath)
+			if renamed {
+				existingPipelines = append(existingPipelines, p.Name)
+			}
+		}
+
+		var oldConfig []byte
+		if stringContains(existingPipelines, p.Name) {
+			c.logger.Debugf("Getting pipeline before setting it: %s\n", p.Name)
+			oldConfig, err = c.flyCommand.GetPipeline(p.Name, false)
+			if err != nil {
+				return concourse.OutResponse{}, err
+			}
 		}
 
 		var setOutput []byte
-		setOutput, err = c.flyCommand.SetPipeline(p.Name, configFilepath, varsFilepaths, p.Vars)
+		setOutput, err = c.flyCommand.SetPipeline(
+			p.Name,
+			configFilepaths[i],
+			varsFilepaths[i],
+			p.Vars,
+			input.Params.CheckCreds || p.CheckCreds,
+		)
+		// The output includes fly's own diff, which duplicates the one
+		// printed below, so it is only written to the build log on failure.
 		c.logger.Debugf("pipeline '%s' set; output:\n\n%s\n", p.Name, string(setOutput))
-		fmt.Fprintf(os.Stderr, "pipeline '%s' set; output:\n\n%s\n", p.Name, string(setOutput))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "pipeline '%s' failed to set; output:\n\n%s\n", p.Name, string(setOutput))
+			return concourse.OutResponse{}, err
+		}
+
+		c.logger.Debugf("Getting pipeline after setting it: %s\n", p.Name)
+		newConfig, err := c.flyCommand.GetPipeline(p.Name, false)
 		if err != nil {
 			return concourse.OutResponse{}, err
 		}
 
+		summary, err := c.printChanges(p, oldConfig, newConfig)
+		if err != nil {
+			return concourse.OutResponse{}, err
+		}
+
+		jobChanges = appendChanges(jobChanges, summary.Jobs)
+		resourceChanges = appendChanges(resourceChanges, summary.Resources)
+
 		if p.Exposed {
 			_, err = c.flyCommand.ExposePipeline(p.Name)
 			if err != nil {
@@ -127,11 +248,11 @@ func (c *Command) Run(input concourse.OutRequest) (concourse.OutResponse, error)
 		c.logger.Debugf("Login successful\n")
 
 		for _, pipeline := range pipelines {
-			if pipeline.TeamName != teamName {
+			if pipeline.TeamName != teamName || validateOnly(input.Params, pipeline) {
 				continue
 			}
 			c.logger.Debugf("Getting pipeline: %s\n", pipeline.Name)
-			outBytes, err := c.flyCommand.GetPipeline(pipeline.Name)
+			outBytes, err := c.flyCommand.GetPipeline(pipeline.Name, false)
 			if err != nil {
 				return concourse.OutResponse{}, err
 			}
@@ -145,9 +266,112 @@ func (c *Command) Run(input concourse.OutRequest) (concourse.OutResponse, error)
 	}
 
 	response := concourse.OutResponse{
-		Version:  pipelineVersions,
-		Metadata: []concourse.Metadata{},
+		Version: pipelineVersions,
+		Metadata: []concourse.Metadata{
+			{Name: "jobs_added", Value: strconv.Itoa(len(jobChanges.Added))},
+			{Name: "jobs_removed", Value: strconv.Itoa(len(jobChanges.Removed))},
+			{Name: "jobs_changed", Value: strconv.Itoa(len(jobChanges.Changed))},
+			{Name: "resources_added", Value: strconv.Itoa(len(resourceChanges.Added))},
+			{Name: "resources_removed", Value: strconv.Itoa(len(resourceChanges.Removed))},
+			{Name: "resources_changed", Value: strconv.Itoa(len(resourceChanges.Changed))},
+		},
 	}
 
 	return response, nil
 }
+
+// configFilepath returns the path of the pipeline's config file or, if it
+// has a config_dir, of the config merged from that directory into mergedDir.
+func (c *Command) configFilepath(p concourse.Pipeline, mergedDir string, index int) (string, error) {
+	if p.ConfigDir == "" {
+		return filepath.Join(c.sourcesDir, p.ConfigFile), nil
+	}
+
+	c.logger.Debugf("Merging pipeline config from directory: %s\n", p.ConfigDir)
+	merged, err := config.Merge(filepath.Join(c.sourcesDir, p.ConfigDir))
+	if err != nil {
+		return "", err
+	}
+
+	mergedFilepath := filepath.Join(mergedDir, fmt.Sprintf("%d.yml", index))
+	err = ioutil.WriteFile(mergedFilepath, merged, 0644)
+	if err != nil {
+		// Untested as it is too hard to force ioutil.WriteFile to error
+		return "", err
+	}
+
+	return mergedFilepath, nil
+}
+
+// validateOnly returns true if the pipeline should be validated but not set,
+// either because the whole put is validate-only or the pipeline itself is.
+func validateOnly(params concourse.OutParams, p concourse.Pipeline) bool {
+	return params.ValidateOnly || p.ValidateOnly
+}
+
+// renamePipeline renames the pipeline from oldName to newName, preserving its
+// build history. It is a no-op if oldName no longer exists or newName already
+// exists, which makes it safe to leave renamed_from in the manifest.
+func (c *Command) renamePipeline(existingPipelines []string, oldName string, newName string) (bool, error) {
+	if !stringContains(existingPipelines, oldName) || stringContains(existingPipelines, newName) {
+		c.logger.Debugf("Not renaming pipeline '%s' to '%s'\n", oldName, newName)
+		return false, nil
+	}
+
+	c.logger.Debugf("Renaming pipeline '%s' to '%s'\n", oldName, newName)
+	_, err := c.flyCommand.RenamePipeline(oldName, newName)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// printChanges writes a colorized diff of the pipeline config to the build
+// log, and returns a summary of the jobs and resources which changed.
+func (c *Command) printChanges(p concourse.Pipeline, oldConfig []byte, newConfig []byte) (diff.Summary, error) {
+	summary, err := diff.Summarize(oldConfig, newConfig)
+	if err != nil {
+		return diff.Summary{}, err
+	}
+
+	unified := diff.Unified(
+		fmt.Sprintf("before/%s/%s", p.TeamName, p.Name),
+		fmt.Sprintf("after/%s/%s", p.TeamName, p.Name),
+		oldConfig,
+		newConfig,
+	)
+
+	if unified == "" {
+		fmt.Fprintf(os.Stderr, "pipeline '%s' unchanged\n", p.Name)
+		return summary, nil
+	}
+
+	fmt.Fprintf(
+		os.Stderr,
+		"pipeline '%s' changed (jobs: %d, resources: %d); diff:\n\n%s\n",
+		p.Name,
+		summary.Jobs.Count(),
+		summary.Resources.Count(),
+		diff.Colorize(unified),
+	)
+
+	return summary, nil
+}
+
+func appendChanges(all diff.Changes, changes diff.Changes) diff.Changes {
+	all.Added = append(all.Added, changes.Added...)
+	all.Removed = append(all.Removed, changes.Removed...)
+	all.Changed = append(all.Changed, changes.Changed...)
+	return all
+}
+
+func stringContains(slice []string, str string) bool {
+	for _, s := range slice {
+		if s == str {
+			return true
+		}
+	}
+
+	return false
+}
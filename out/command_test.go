@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/concourse/concourse-pipeline-resource/concourse"
 	"github.com/concourse/concourse-pipeline-resource/fly/flyfakes"
@@ -101,7 +102,7 @@ pipeline3: foo
 			},
 		}
 
-		fakeFlyCommand.GetPipelineStub = func(name string) ([]byte, error) {
+		fakeFlyCommand.GetPipelineStub = func(name string, asJSON bool) ([]byte, error) {
 			defer GinkgoRecover()
 			ginkgoLogger.Debugf("GetPipelineStub for: %s\n", name)
 
@@ -179,11 +180,12 @@ pipeline3: foo
 		Expect(fakeFlyCommand.SetPipelineCallCount()).To(Equal(len(pipelines)))
 
 		for i, p := range pipelines {
-			name, configFilepath, varsFilepaths, vars := fakeFlyCommand.SetPipelineArgsForCall(i)
+			name, configFilepath, varsFilepaths, vars, checkCreds := fakeFlyCommand.SetPipelineArgsForCall(i)
 			_, tname, _, _, _ := fakeFlyCommand.LoginArgsForCall(i)
 			Expect(name).To(Equal(p.Name))
 			Expect(tname).To(Equal(p.TeamName))
 			Expect(configFilepath).To(Equal(filepath.Join(sourcesDir, p.ConfigFile)))
+			Expect(checkCreds).To(BeFalse())
 
 			// the first pipeline has vars files
 			if i == 0 {
@@ -207,6 +209,408 @@ pipeline3: foo
 		}
 	})
 
+	It("validates every pipeline before setting any", func() {
+		_, err := command.Run(outRequest)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeFlyCommand.ValidatePipelineCallCount()).To(Equal(len(pipelines)))
+
+		for i, p := range pipelines {
+			configFilepath, _, vars, strict := fakeFlyCommand.ValidatePipelineArgsForCall(i)
+			Expect(configFilepath).To(Equal(filepath.Join(sourcesDir, p.ConfigFile)))
+			Expect(vars).To(Equal(p.Vars))
+			Expect(strict).To(BeFalse())
+		}
+
+		varsFilepaths := fakeFlyCommand.Invocations()["ValidatePipeline"][0][1]
+		Expect(varsFilepaths).To(Equal([]string{
+			filepath.Join(sourcesDir, pipelines[0].VarsFiles[0]),
+			filepath.Join(sourcesDir, pipelines[0].VarsFiles[1]),
+		}))
+	})
+
+	Context("when vars are shared by the pipelines of a team and by every pipeline", func() {
+		var validatedVarsFiles [][]string
+
+		BeforeEach(func() {
+			outRequest.Source.Teams[1].VarsFiles = []string{"team_vars.yml"}
+			outRequest.Source.Teams[1].Vars = map[string]interface{}{
+				"launch-missiles": false,
+				"registry":        "team.example.com",
+			}
+
+			outRequest.Params.VarsFiles = []string{"shared_vars.yml"}
+			outRequest.Params.Vars = map[string]interface{}{
+				"registry": "example.com",
+				"slack":    "#ci",
+			}
+
+			validatedVarsFiles = nil
+			fakeFlyCommand.ValidatePipelineStub = func(_ string, varsFilepaths []string, _ map[string]interface{}, _ bool) ([]byte, error) {
+				validatedVarsFiles = append(validatedVarsFiles, varsFiles(varsFilepaths, sourcesDir))
+				return nil, nil
+			}
+		})
+
+		It("validates and sets each pipeline with the shared vars in files before its own", func() {
+			_, err := command.Run(outRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			for i, p := range pipelines {
+				_, validateVarsFilepaths, validateVars, _ := fakeFlyCommand.ValidatePipelineArgsForCall(i)
+				_, _, setVarsFilepaths, setVars, _ := fakeFlyCommand.SetPipelineArgsForCall(i)
+				Expect(setVarsFilepaths).To(Equal(validateVarsFilepaths))
+				Expect(setVars).To(Equal(validateVars))
+				Expect(validateVars).To(Equal(p.Vars))
+			}
+
+			Expect(validatedVarsFiles[0]).To(HaveLen(4))
+			Expect(validatedVarsFiles[0][0]).To(Equal(filepath.Join(sourcesDir, "shared_vars.yml")))
+			Expect(validatedVarsFiles[0][1]).To(MatchYAML("{registry: example.com, slack: '#ci'}"))
+			Expect(validatedVarsFiles[0][2]).To(Equal(filepath.Join(sourcesDir, pipelines[0].VarsFiles[0])))
+			Expect(validatedVarsFiles[0][3]).To(Equal(filepath.Join(sourcesDir, pipelines[0].VarsFiles[1])))
+
+			Expect(validatedVarsFiles[2]).To(HaveLen(4))
+			Expect(validatedVarsFiles[2][0]).To(Equal(filepath.Join(sourcesDir, "shared_vars.yml")))
+			Expect(validatedVarsFiles[2][1]).To(MatchYAML("{registry: example.com, slack: '#ci'}"))
+			Expect(validatedVarsFiles[2][2]).To(Equal(filepath.Join(sourcesDir, "team_vars.yml")))
+			Expect(validatedVarsFiles[2][3]).To(MatchYAML("{launch-missiles: false, registry: team.example.com}"))
+		})
+	})
+
+	Context("when vars are provided from the environment", func() {
+		var validatedVarsFiles [][]string
+
+		BeforeEach(func() {
+			Expect(os.Setenv("BUILD_TEAM_NAME", "some-build-team")).To(Succeed())
+			Expect(os.Setenv("ATC_EXTERNAL_URL", "https://concourse.example.com")).To(Succeed())
+			Expect(os.Setenv("SOME_REGISTRY", "registry.example.com")).To(Succeed())
+			Expect(os.Setenv("SOME_CHANNEL", "#some-channel")).To(Succeed())
+
+			outRequest.Params.VarsFromEnv = map[string]string{
+				"registry": "SOME_REGISTRY",
+				"channel":  "SOME_CHANNEL",
+			}
+			outRequest.Params.Vars = map[string]interface{}{
+				"channel": "#ci",
+			}
+			outRequest.Params.Pipelines[2].VarsFromEnv = map[string]string{
+				"channel":  "SOME_CHANNEL",
+				"registry": "SOME_CHANNEL",
+			}
+
+			validatedVarsFiles = nil
+			fakeFlyCommand.ValidatePipelineStub = func(_ string, varsFilepaths []string, _ map[string]interface{}, _ bool) ([]byte, error) {
+				validatedVarsFiles = append(validatedVarsFiles, varsFiles(varsFilepaths, sourcesDir))
+				return nil, nil
+			}
+		})
+
+		AfterEach(func() {
+			for _, key := range []string{"BUILD_TEAM_NAME", "ATC_EXTERNAL_URL", "SOME_REGISTRY", "SOME_CHANNEL"} {
+				Expect(os.Unsetenv(key)).To(Succeed())
+			}
+		})
+
+		It("validates and sets each pipeline with the build metadata in a file before the others", func() {
+			_, err := command.Run(outRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			buildMetadataVars := `
+resource_build_team_name: some-build-team
+resource_atc_external_url: https://concourse.example.com
+`
+			// Shared vars from the environment take precedence below the
+			// shared vars.
+			sharedVars := "{registry: registry.example.com, channel: '#ci'}"
+
+			Expect(validatedVarsFiles[0]).To(HaveLen(4))
+			Expect(validatedVarsFiles[0][0]).To(MatchYAML(buildMetadataVars))
+			Expect(validatedVarsFiles[0][1]).To(MatchYAML(sharedVars))
+			Expect(validatedVarsFiles[0][2]).To(Equal(filepath.Join(sourcesDir, pipelines[0].VarsFiles[0])))
+			Expect(validatedVarsFiles[0][3]).To(Equal(filepath.Join(sourcesDir, pipelines[0].VarsFiles[1])))
+
+			_, _, vars, _ := fakeFlyCommand.ValidatePipelineArgsForCall(0)
+			Expect(vars).To(BeNil())
+
+			Expect(validatedVarsFiles[2]).To(HaveLen(2))
+			Expect(validatedVarsFiles[2][0]).To(MatchYAML(buildMetadataVars))
+			Expect(validatedVarsFiles[2][1]).To(MatchYAML(sharedVars))
+
+			// Vars from the environment of a pipeline likewise take precedence
+			// below its vars.
+			_, _, _, vars, _ = fakeFlyCommand.SetPipelineArgsForCall(2)
+			Expect(vars).To(Equal(map[string]interface{}{
+				"registry":        "#some-channel",
+				"channel":         "#some-channel",
+				"launch-missiles": true,
+			}))
+		})
+
+		Context("when a vars file of a pipeline defines a var from the build metadata", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(
+					filepath.Join(sourcesDir, "vars_1.yml"),
+					[]byte("resource_atc_external_url: https://other.example.com\n"),
+					0644,
+				)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("passes the vars file after the build metadata, so that it takes precedence", func() {
+				_, err := command.Run(outRequest)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(validatedVarsFiles[0]).To(HaveLen(4))
+				Expect(validatedVarsFiles[0][0]).To(ContainSubstring("resource_atc_external_url: https://concourse.example.com"))
+				Expect(validatedVarsFiles[0][2]).To(Equal(filepath.Join(sourcesDir, "vars_1.yml")))
+
+				_, _, vars, _ := fakeFlyCommand.ValidatePipelineArgsForCall(0)
+				Expect(vars).NotTo(HaveKey("resource_atc_external_url"))
+			})
+		})
+
+		Context("when an environment variable is not set", func() {
+			BeforeEach(func() {
+				outRequest.Params.Pipelines[2].VarsFromEnv["missing"] = "SOME_MISSING_VAR"
+			})
+
+			It("returns an error without validating any pipeline", func() {
+				_, err := command.Run(outRequest)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(Equal(
+					"pipeline 'pipeline-3': environment variable SOME_MISSING_VAR for var missing is not set",
+				))
+				Expect(fakeFlyCommand.ValidatePipelineCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Context("when a pipeline has vars files which are not YAML", func() {
+		var validatedVars []string
+
+		BeforeEach(func() {
+			err := ioutil.WriteFile(filepath.Join(sourcesDir, "vars.json"), []byte(`{"foo": "bar"}`), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			pipelines[0].VarsFiles = []string{"vars_1.yml", "vars.json"}
+
+			validatedVars = nil
+			fakeFlyCommand.ValidatePipelineStub = func(_ string, varsFilepaths []string, _ map[string]interface{}, _ bool) ([]byte, error) {
+				if validatedVars == nil {
+					b, err := ioutil.ReadFile(varsFilepaths[1])
+					Expect(err).NotTo(HaveOccurred())
+					validatedVars = []string{varsFilepaths[0], string(b)}
+				}
+				return nil, nil
+			}
+		})
+
+		It("validates and sets the pipeline with the vars converted to YAML in order", func() {
+			_, err := command.Run(outRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(validatedVars[0]).To(Equal(filepath.Join(sourcesDir, "vars_1.yml")))
+			Expect(validatedVars[1]).To(MatchYAML("foo: bar"))
+
+			_, validatedFilepaths, _, _ := fakeFlyCommand.ValidatePipelineArgsForCall(0)
+			_, _, setFilepaths, _, _ := fakeFlyCommand.SetPipelineArgsForCall(0)
+			Expect(setFilepaths).To(Equal(validatedFilepaths))
+
+			_, err = os.Stat(setFilepaths[1])
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		Context("when a vars file cannot be converted", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(filepath.Join(sourcesDir, "vars.json"), []byte(`{{`), 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns an error without validating any pipeline", func() {
+				_, err := command.Run(outRequest)
+				Expect(err).To(HaveOccurred())
+
+				Expect(fakeFlyCommand.ValidatePipelineCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Context("when a pipeline has a config dir", func() {
+		var validatedConfig string
+
+		BeforeEach(func() {
+			splitDir := filepath.Join(sourcesDir, "split")
+			err := os.MkdirAll(filepath.Join(splitDir, "jobs"), 0755)
+			Expect(err).NotTo(HaveOccurred())
+
+			files := map[string]string{
+				"manifest.yml": `sections:
+- key: resources
+  file: resources.yml
+- key: jobs
+  files: [jobs/a.yml, jobs/b.yml]
+`,
+				"resources.yml": "- name: repo\n  type: git\n",
+				"jobs/a.yml":    "name: a\nplan: []\n",
+				"jobs/b.yml":    "name: b\nplan: []\n",
+			}
+			for name, contents := range files {
+				err = ioutil.WriteFile(filepath.Join(splitDir, name), []byte(contents), 0644)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			pipelines[0].ConfigFile = ""
+			pipelines[0].ConfigDir = "split"
+
+			fakeFlyCommand.ValidatePipelineStub = func(configFilepath string, _ []string, _ map[string]interface{}, _ bool) ([]byte, error) {
+				if validatedConfig == "" {
+					b, err := ioutil.ReadFile(configFilepath)
+					Expect(err).NotTo(HaveOccurred())
+					validatedConfig = string(b)
+				}
+				return nil, nil
+			}
+		})
+
+		It("validates and sets the config merged from the directory", func() {
+			_, err := command.Run(outRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(validatedConfig).To(MatchYAML(`resources:
+- name: repo
+  type: git
+jobs:
+- name: a
+  plan: []
+- name: b
+  plan: []
+`))
+
+			validatedFilepath, _, _, _ := fakeFlyCommand.ValidatePipelineArgsForCall(0)
+			_, setFilepath, _, _, _ := fakeFlyCommand.SetPipelineArgsForCall(0)
+			Expect(setFilepath).To(Equal(validatedFilepath))
+
+			_, err = os.Stat(setFilepath)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		Context("when the directory has no manifest", func() {
+			BeforeEach(func() {
+				pipelines[0].ConfigDir = "missing"
+			})
+
+			It("returns an error without validating any pipeline", func() {
+				_, err := command.Run(outRequest)
+				Expect(err).To(HaveOccurred())
+
+				Expect(fakeFlyCommand.ValidatePipelineCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Context("when a pipeline fails validation", func() {
+		var (
+			expectedErr error
+		)
+
+		BeforeEach(func() {
+			expectedErr = fmt.Errorf("invalid pipeline")
+			fakeFlyCommand.ValidatePipelineReturnsOnCall(2, nil, expectedErr)
+		})
+
+		It("returns an error without setting any pipeline", func() {
+			_, err := command.Run(outRequest)
+			Expect(err).To(Equal(expectedErr))
+
+			Expect(fakeFlyCommand.LoginCallCount()).To(Equal(0))
+			Expect(fakeFlyCommand.SetPipelineCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when strict validation is requested", func() {
+		BeforeEach(func() {
+			outRequest.Params.StrictValidation = true
+		})
+
+		It("validates pipelines in strict mode", func() {
+			_, err := command.Run(outRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, _, strict := fakeFlyCommand.ValidatePipelineArgsForCall(0)
+			Expect(strict).To(BeTrue())
+		})
+	})
+
+	Context("when check creds is requested for all pipelines", func() {
+		BeforeEach(func() {
+			outRequest.Params.CheckCreds = true
+		})
+
+		It("sets every pipeline with check creds", func() {
+			_, err := command.Run(outRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			for i := range pipelines {
+				_, _, _, _, checkCreds := fakeFlyCommand.SetPipelineArgsForCall(i)
+				Expect(checkCreds).To(BeTrue())
+			}
+		})
+	})
+
+	Context("when check creds is requested for one pipeline", func() {
+		BeforeEach(func() {
+			outRequest.Params.Pipelines[1].CheckCreds = true
+		})
+
+		It("sets only that pipeline with check creds", func() {
+			_, err := command.Run(outRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, _, _, checkCreds := fakeFlyCommand.SetPipelineArgsForCall(0)
+			Expect(checkCreds).To(BeFalse())
+
+			_, _, _, _, checkCreds = fakeFlyCommand.SetPipelineArgsForCall(1)
+			Expect(checkCreds).To(BeTrue())
+		})
+	})
+
+	Context("when validate only is requested for all pipelines", func() {
+		BeforeEach(func() {
+			outRequest.Params.ValidateOnly = true
+		})
+
+		It("validates but does not set or version any pipeline", func() {
+			response, err := command.Run(outRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeFlyCommand.ValidatePipelineCallCount()).To(Equal(len(pipelines)))
+			Expect(fakeFlyCommand.SetPipelineCallCount()).To(Equal(0))
+			Expect(fakeFlyCommand.UnpausePipelineCallCount()).To(Equal(0))
+			Expect(fakeFlyCommand.ExposePipelineCallCount()).To(Equal(0))
+			Expect(fakeFlyCommand.GetPipelineCallCount()).To(Equal(0))
+			Expect(response.Version).To(BeEmpty())
+		})
+	})
+
+	Context("when validate only is requested for one pipeline", func() {
+		BeforeEach(func() {
+			outRequest.Params.Pipelines[1].ValidateOnly = true
+		})
+
+		It("validates but does not set or version that pipeline", func() {
+			response, err := command.Run(outRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeFlyCommand.ValidatePipelineCallCount()).To(Equal(len(pipelines)))
+			Expect(fakeFlyCommand.SetPipelineCallCount()).To(Equal(len(pipelines) - 1))
+			Expect(fakeFlyCommand.UnpausePipelineCallCount()).To(Equal(0))
+			Expect(response.Version).NotTo(HaveKey(apiPipelines[1]))
+			Expect(response.Version).To(HaveKey(apiPipelines[0]))
+		})
+	})
+
 	It("returns provided version", func() {
 		response, err := command.Run(outRequest)
 
@@ -223,6 +627,167 @@ pipeline3: foo
 		Expect(response.Metadata).NotTo(BeNil())
 	})
 
+	Context("when pipelines already exist", func() {
+		var (
+			oldConfig string
+			newConfig string
+		)
+
+		BeforeEach(func() {
+			oldConfig = `---
+resources:
+- name: repo
+  type: git
+jobs:
+- name: unit
+  plan: [{get: repo}]
+- name: deploy
+  plan: [{get: repo}]
+`
+			newConfig = `---
+resources:
+- name: repo
+  type: git
+- name: timer
+  type: time
+jobs:
+- name: unit
+  plan: [{get: repo, trigger: true}]
+`
+
+			fakeFlyCommand.PipelinesReturns([]string{apiPipelines[0]}, nil)
+
+			getCount := make(map[string]int)
+			fakeFlyCommand.GetPipelineStub = func(name string, asJSON bool) ([]byte, error) {
+				getCount[name]++
+				if name == apiPipelines[0] && getCount[name] == 1 {
+					return []byte(oldConfig), nil
+				}
+				return []byte(newConfig), nil
+			}
+		})
+
+		It("gets existing pipelines before and after setting them", func() {
+			_, err := command.Run(outRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			name, _ := fakeFlyCommand.GetPipelineArgsForCall(0)
+			Expect(name).To(Equal(apiPipelines[0]))
+			name, _ = fakeFlyCommand.GetPipelineArgsForCall(1)
+			Expect(name).To(Equal(apiPipelines[0]))
+			name, _ = fakeFlyCommand.GetPipelineArgsForCall(2)
+			Expect(name).To(Equal(apiPipelines[1]))
+		})
+
+		It("returns counts of changed jobs and resources in the metadata", func() {
+			response, err := command.Run(outRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			// The first pipeline changes; the others are new.
+			Expect(response.Metadata).To(ConsistOf(
+				concourse.Metadata{Name: "jobs_added", Value: "2"},
+				concourse.Metadata{Name: "jobs_removed", Value: "1"},
+				concourse.Metadata{Name: "jobs_changed", Value: "1"},
+				concourse.Metadata{Name: "resources_added", Value: "5"},
+				concourse.Metadata{Name: "resources_removed", Value: "0"},
+				concourse.Metadata{Name: "resources_changed", Value: "0"},
+			))
+		})
+
+		Context("when the existing pipeline fails to parse", func() {
+			BeforeEach(func() {
+				oldConfig = "{{"
+			})
+
+			It("returns an error", func() {
+				_, err := command.Run(outRequest)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Context("when a pipeline has been renamed", func() {
+		BeforeEach(func() {
+			outRequest.Params.Pipelines[0].RenamedFrom = "old-pipeline-1"
+		})
+
+		Context("when the old pipeline exists and the new one does not", func() {
+			BeforeEach(func() {
+				fakeFlyCommand.PipelinesReturns([]string{"old-pipeline-1"}, nil)
+			})
+
+			It("renames the pipeline before setting it", func() {
+				_, err := command.Run(outRequest)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeFlyCommand.RenamePipelineCallCount()).To(Equal(1))
+				oldName, newName := fakeFlyCommand.RenamePipelineArgsForCall(0)
+				Expect(oldName).To(Equal("old-pipeline-1"))
+				Expect(newName).To(Equal(apiPipelines[0]))
+			})
+
+			Context("when renaming returns an error", func() {
+				var (
+					expectedErr error
+				)
+
+				BeforeEach(func() {
+					expectedErr = fmt.Errorf("rename failed")
+					fakeFlyCommand.RenamePipelineReturns(nil, expectedErr)
+				})
+
+				It("returns an error without setting the pipeline", func() {
+					_, err := command.Run(outRequest)
+					Expect(err).To(Equal(expectedErr))
+
+					Expect(fakeFlyCommand.SetPipelineCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when the new pipeline already exists", func() {
+			BeforeEach(func() {
+				fakeFlyCommand.PipelinesReturns([]string{"old-pipeline-1", apiPipelines[0]}, nil)
+			})
+
+			It("does not rename the pipeline", func() {
+				_, err := command.Run(outRequest)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeFlyCommand.RenamePipelineCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the old pipeline does not exist", func() {
+			BeforeEach(func() {
+				fakeFlyCommand.PipelinesReturns([]string{}, nil)
+			})
+
+			It("does not rename the pipeline", func() {
+				_, err := command.Run(outRequest)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeFlyCommand.RenamePipelineCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when listing pipelines returns an error", func() {
+			var (
+				expectedErr error
+			)
+
+			BeforeEach(func() {
+				expectedErr = fmt.Errorf("some error")
+				fakeFlyCommand.PipelinesReturns(nil, expectedErr)
+			})
+
+			It("returns an error", func() {
+				_, err := command.Run(outRequest)
+				Expect(err).To(Equal(expectedErr))
+			})
+		})
+	})
+
 	Context("when insecure parses as true", func() {
 		BeforeEach(func() {
 			outRequest.Source.Insecure = "true"
@@ -307,3 +872,21 @@ pipeline3: foo
 		})
 	})
 })
+
+// varsFiles returns the vars files in sourcesDir by path, and those generated
+// by their contents, as they are removed after the put.
+func varsFiles(varsFilepaths []string, sourcesDir string) []string {
+	var files []string
+	for _, f := range varsFilepaths {
+		if strings.HasPrefix(f, sourcesDir) {
+			files = append(files, f)
+			continue
+		}
+
+		b, err := ioutil.ReadFile(f)
+		Expect(err).NotTo(HaveOccurred())
+		files = append(files, string(b))
+	}
+
+	return files
+}
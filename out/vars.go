@@ -0,0 +1,29 @@
+package out
+
+import "os"
+
+// builtinVars maps the vars available to every pipeline to the environment
+// variables exposing the metadata of the build setting the pipeline. They are
+// prefixed so as not to shadow vars of the same name from a credential
+// manager.
+var builtinVars = map[string]string{
+	"resource_build_id":            "BUILD_ID",
+	"resource_build_name":          "BUILD_NAME",
+	"resource_build_job_name":      "BUILD_JOB_NAME",
+	"resource_build_pipeline_name": "BUILD_PIPELINE_NAME",
+	"resource_build_team_name":     "BUILD_TEAM_NAME",
+	"resource_atc_external_url":    "ATC_EXTERNAL_URL",
+}
+
+// buildMetadataVars returns the builtin vars for which the build metadata is
+// available.
+func buildMetadataVars() map[string]interface{} {
+	vars := map[string]interface{}{}
+	for name, envKey := range builtinVars {
+		if value, found := os.LookupEnv(envKey); found {
+			vars[name] = value
+		}
+	}
+
+	return vars
+}
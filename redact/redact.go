@@ -0,0 +1,135 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Placeholder replaces every redacted value.
+const Placeholder = "REDACTED"
+
+// DefaultKeys are the keys whose values are always redacted. A key matches if
+// it is equal to one of these or ends with an underscore followed by one, so
+// that e.g. github_token and aws_secret_access_key are also redacted.
+var DefaultKeys = []string{
+	"password",
+	"passphrase",
+	"private_key",
+	"secret",
+	"secret_access_key",
+	"client_secret",
+	"token",
+	"access_token",
+	"api_key",
+}
+
+// varReference matches a value which is only a reference to a var, such as
+// ((git-password)), which is not itself a secret.
+var varReference = regexp.MustCompile(`^\(\([^()]+\)\)$`)
+
+type Redactor struct {
+	keys     []string
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor returns a Redactor for the default keys along with the given
+// keys, and which also redacts any part of a value matching the given
+// regular expressions.
+func NewRedactor(keys []string, patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+
+	for _, k := range append(append([]string{}, DefaultKeys...), keys...) {
+		r.keys = append(r.keys, normalizeKey(k))
+	}
+
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	return r, nil
+}
+
+// Redact replaces secret values in the config in place, and returns the paths
+// of the values which were redacted, e.g. resources[0].source.password.
+func (r *Redactor) Redact(doc yaml.MapSlice) []string {
+	var paths []string
+	r.redactMap(doc, "", &paths)
+	return paths
+}
+
+func (r *Redactor) redactMap(m yaml.MapSlice, path string, paths *[]string) {
+	for i, item := range m {
+		key := fmt.Sprint(item.Key)
+		itemPath := key
+		if path != "" {
+			itemPath = path + "." + key
+		}
+
+		if r.secretKey(key) && redactable(item.Value) {
+			m[i].Value = Placeholder
+			*paths = append(*paths, itemPath)
+			continue
+		}
+
+		m[i].Value = r.redactValue(item.Value, itemPath, paths)
+	}
+}
+
+func (r *Redactor) redactValue(v interface{}, path string, paths *[]string) interface{} {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		r.redactMap(v, path, paths)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redactValue(item, fmt.Sprintf("%s[%d]", path, i), paths)
+		}
+	case string:
+		redacted := v
+		for _, re := range r.patterns {
+			redacted = re.ReplaceAllString(redacted, Placeholder)
+		}
+
+		if redacted != v {
+			*paths = append(*paths, path)
+			return redacted
+		}
+	}
+
+	return v
+}
+
+func (r *Redactor) secretKey(key string) bool {
+	key = normalizeKey(key)
+	for _, k := range r.keys {
+		if key == k || strings.HasSuffix(key, "_"+k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactable returns true for non-boolean scalar values which are not var
+// references.
+// Mappings and sequences under a secret key are still searched.
+func redactable(v interface{}) bool {
+	switch v := v.(type) {
+	case nil, bool, yaml.MapSlice, []interface{}:
+		return false
+	case string:
+		return v != "" && !varReference.MatchString(strings.TrimSpace(v))
+	default:
+		return true
+	}
+}
+
+func normalizeKey(key string) string {
+	return strings.Replace(strings.ToLower(key), "-", "_", -1)
+}
@@ -2,6 +2,9 @@ package validator
 
 import (
 	"fmt"
+	"path"
+	"regexp"
+	"text/template"
 
 	"github.com/concourse/concourse-pipeline-resource/concourse"
 )
@@ -11,5 +14,56 @@ func ValidateIn(input concourse.InRequest) error {
 		return fmt.Errorf("%s must be provided in source", "target")
 	}
 
+	switch input.Params.Format {
+	case "", "yaml", "json":
+	default:
+		return fmt.Errorf("%s must be one of yaml or json", "format")
+	}
+
+	switch input.Params.Layout {
+	case "", "flat", "nested":
+	default:
+		_, err := template.New("layout").Parse(input.Params.Layout)
+		if err != nil {
+			return fmt.Errorf("%s must be flat, nested or a valid template: %v", "layout", err)
+		}
+	}
+
+	err := validateGlobs("teams", input.Params.Teams)
+	if err != nil {
+		return err
+	}
+
+	err = validateGlobs("pipelines", input.Params.Pipelines)
+	if err != nil {
+		return err
+	}
+
+	if !input.Params.Redact && (len(input.Params.RedactKeys) > 0 || len(input.Params.RedactPatterns) > 0) {
+		return fmt.Errorf("%s must be true if redact_keys or redact_patterns is provided", "redact")
+	}
+
+	for i, pattern := range input.Params.RedactPatterns {
+		_, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("%s[%d] must be a valid regular expression: %v", "redact_patterns", i, err)
+		}
+	}
+
+	if input.Params.FailOnDrift && input.Params.ExpectedManifest == "" {
+		return fmt.Errorf("%s must be provided if fail_on_drift is true", "expected_manifest")
+	}
+
 	return ValidateTeams(input.Source.Teams)
 }
+
+func validateGlobs(name string, patterns []string) error {
+	for i, pattern := range patterns {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return fmt.Errorf("%s[%d] must be a valid glob: %v", name, i, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package validator_test
+
+import (
+	"github.com/concourse/concourse-pipeline-resource/concourse"
+	"github.com/concourse/concourse-pipeline-resource/validator"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateIn", func() {
+	var (
+		inRequest concourse.InRequest
+	)
+
+	BeforeEach(func() {
+		inRequest = concourse.InRequest{
+			Source: concourse.Source{
+				Target: "some target",
+				Teams: []concourse.Team{
+					{
+						Name:     "some team",
+						Username: "some username",
+						Password: "some password",
+					},
+				},
+			},
+		}
+	})
+
+	It("returns without error", func() {
+		Expect(validator.ValidateIn(inRequest)).Should(Succeed())
+	})
+
+	Context("when no target is provided", func() {
+		BeforeEach(func() {
+			inRequest.Source.Target = ""
+		})
+
+		It("returns an error", func() {
+			err := validator.ValidateIn(inRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(MatchRegexp(".*target.*provided"))
+		})
+	})
+
+	Context("when format is json", func() {
+		BeforeEach(func() {
+			inRequest.Params.Format = "json"
+		})
+
+		It("returns without error", func() {
+			Expect(validator.ValidateIn(inRequest)).Should(Succeed())
+		})
+	})
+
+	Context("when layout is a valid template", func() {
+		BeforeEach(func() {
+			inRequest.Params.Layout = "{{.Team}}/{{.Pipeline}}"
+		})
+
+		It("returns without error", func() {
+			Expect(validator.ValidateIn(inRequest)).Should(Succeed())
+		})
+	})
+
+	Context("when layout is an invalid template", func() {
+		BeforeEach(func() {
+			inRequest.Params.Layout = "{{.Team"
+		})
+
+		It("returns an error", func() {
+			err := validator.ValidateIn(inRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(MatchRegexp(".*layout.*flat, nested or a valid template"))
+		})
+	})
+
+	Context("when a team selector is not a valid glob", func() {
+		BeforeEach(func() {
+			inRequest.Params.Teams = []string{"main", "[team"}
+		})
+
+		It("returns an error", func() {
+			err := validator.ValidateIn(inRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(MatchRegexp(".*teams\\[1\\].*valid glob"))
+		})
+	})
+
+	Context("when a pipeline selector is not a valid glob", func() {
+		BeforeEach(func() {
+			inRequest.Params.Pipelines = []string{"[pipeline"}
+		})
+
+		It("returns an error", func() {
+			err := validator.ValidateIn(inRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(MatchRegexp(".*pipelines\\[0\\].*valid glob"))
+		})
+	})
+
+	Context("when format is not recognized", func() {
+		BeforeEach(func() {
+			inRequest.Params.Format = "toml"
+		})
+
+		It("returns an error", func() {
+			err := validator.ValidateIn(inRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(MatchRegexp(".*format.*yaml or json"))
+		})
+	})
+	Context("when redact patterns are provided without redact", func() {
+		BeforeEach(func() {
+			inRequest.Params.RedactPatterns = []string{"secret"}
+		})
+
+		It("returns an error", func() {
+			err := validator.ValidateIn(inRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(MatchRegexp(".*redact.*true"))
+		})
+	})
+
+	Context("when a redact pattern is not a valid regular expression", func() {
+		BeforeEach(func() {
+			inRequest.Params.Redact = true
+			inRequest.Params.RedactPatterns = []string{"("}
+		})
+
+		It("returns an error", func() {
+			err := validator.ValidateIn(inRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(MatchRegexp(".*redact_patterns.*regular expression"))
+		})
+	})
+	Context("when fail on drift is true without an expected manifest", func() {
+		BeforeEach(func() {
+			inRequest.Params.FailOnDrift = true
+		})
+
+		It("returns an error", func() {
+			err := validator.ValidateIn(inRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(MatchRegexp(".*expected_manifest.*provided"))
+		})
+	})
+})
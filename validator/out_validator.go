@@ -2,6 +2,7 @@ package validator
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/concourse/concourse-pipeline-resource/concourse"
 )
@@ -21,40 +22,75 @@ func ValidateOut(input concourse.OutRequest) error {
 		return fmt.Errorf("%s must be provided in source", "target")
 	}
 
-	var pipelinesFilePresent bool
-	var pipelinesPresent bool
+	sources := 0
+	if len(input.Params.PipelinesFile) > 0 {
+		sources++
+	}
+
+	for i, pattern := range input.Params.PipelinesFile {
+		if pattern == "" {
+			return fmt.Errorf("%s must be non-empty for pipelines_file[%d]", "pattern", i)
+		}
+
+		_, err := filepath.Match(pattern, "")
+		if err != nil {
+			return fmt.Errorf("pipelines_file[%d] must be a valid glob pattern: %s", i, err)
+		}
+	}
 
-	if input.Params.PipelinesFile != "" {
-		pipelinesFilePresent = true
+	if input.Params.PipelinesDir != "" {
+		sources++
 	}
 
 	if input.Params.Pipelines != nil && len(input.Params.Pipelines) > 0 {
-		pipelinesPresent = true
+		sources++
 	}
 
-	if !(pipelinesPresent || pipelinesFilePresent) {
+	if sources == 0 {
 		return fmt.Errorf(
-			"pipelines must be provided via either %s or %s",
+			"pipelines must be provided via either %s, %s or %s",
 			"pipelines",
 			"pipelines_file",
+			"pipelines_dir",
 		)
 	}
 
-	if pipelinesPresent && pipelinesFilePresent {
+	if sources > 1 {
 		return fmt.Errorf(
-			"pipelines must be provided via one of either %s or %s",
+			"pipelines must be provided via one of either %s, %s or %s",
 			"pipelines",
 			"pipelines_file",
+			"pipelines_dir",
 		)
 	}
 
+	for i, v := range input.Params.VarsFiles {
+		if v == "" {
+			return fmt.Errorf("%s must be non-empty for vars_files[%d]", "vars file", i)
+		}
+	}
+
+	for name, envKey := range input.Params.VarsFromEnv {
+		if envKey == "" {
+			return fmt.Errorf("%s must be non-empty for vars_from_env.%s", "environment variable", name)
+		}
+	}
+
 	for i, p := range input.Params.Pipelines {
 		if p.Name == "" {
 			return fmt.Errorf("%s must be provided for pipeline[%d]", "name", i)
 		}
 
-		if p.ConfigFile == "" {
-			return fmt.Errorf("%s must be provided for pipeline[%d]", "config_file", i)
+		if p.RenamedFrom == p.Name {
+			return fmt.Errorf("%s must differ from name for pipeline[%d]", "renamed_from", i)
+		}
+
+		if p.ConfigFile == "" && p.ConfigDir == "" {
+			return fmt.Errorf("%s or %s must be provided for pipeline[%d]", "config_file", "config_dir", i)
+		}
+
+		if p.ConfigFile != "" && p.ConfigDir != "" {
+			return fmt.Errorf("only one of %s or %s must be provided for pipeline[%d]", "config_file", "config_dir", i)
 		}
 
 		if p.TeamName == "" {
@@ -65,6 +101,17 @@ func ValidateOut(input concourse.OutRequest) error {
 			return fmt.Errorf("team name '%s' not found in source team names: %v", p.TeamName, sourceTeamNames)
 		}
 
+		for name, envKey := range p.VarsFromEnv {
+			if envKey == "" {
+				return fmt.Errorf(
+					"%s must be non-empty for pipeline[%d].vars_from_env.%s",
+					"environment variable",
+					i,
+					name,
+				)
+			}
+		}
+
 		// vars files can be nil as it is optional.
 		if p.VarsFiles != nil {
 			// However, if it is provided it must be non-empty
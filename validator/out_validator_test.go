@@ -115,7 +115,7 @@ var _ = Describe("ValidateOut", func() {
 
 	Context("when pipelines_file param is also provided", func() {
 		BeforeEach(func() {
-			outRequest.Params.PipelinesFile = "some-file"
+			outRequest.Params.PipelinesFile = []string{"some-file"}
 		})
 
 		It("returns an error", func() {
@@ -126,6 +126,156 @@ var _ = Describe("ValidateOut", func() {
 		})
 	})
 
+	Context("when config dir is provided instead of config file", func() {
+		BeforeEach(func() {
+			outRequest.Params.Pipelines[0].ConfigFile = ""
+			outRequest.Params.Pipelines[0].ConfigDir = "some dir"
+		})
+
+		It("returns without error", func() {
+			Expect(validator.ValidateOut(outRequest)).Should(Succeed())
+		})
+	})
+
+	Context("when neither config file nor config dir is provided", func() {
+		BeforeEach(func() {
+			outRequest.Params.Pipelines[0].ConfigFile = ""
+		})
+
+		It("returns an error", func() {
+			err := validator.ValidateOut(outRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(MatchRegexp(".*config_file.*config_dir.*provided"))
+		})
+	})
+
+	Context("when both config file and config dir are provided", func() {
+		BeforeEach(func() {
+			outRequest.Params.Pipelines[0].ConfigDir = "some dir"
+		})
+
+		It("returns an error", func() {
+			err := validator.ValidateOut(outRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(MatchRegexp(".*only one.*config_file.*config_dir"))
+		})
+	})
+
+	Context("when pipelines_dir param is provided instead", func() {
+		BeforeEach(func() {
+			outRequest.Params.Pipelines = nil
+			outRequest.Params.PipelinesDir = "some-dir"
+		})
+
+		It("returns without error", func() {
+			Expect(validator.ValidateOut(outRequest)).Should(Succeed())
+		})
+
+		Context("when pipelines_file param is also provided", func() {
+			BeforeEach(func() {
+				outRequest.Params.PipelinesFile = []string{"some-file"}
+			})
+
+			It("returns an error", func() {
+				err := validator.ValidateOut(outRequest)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(MatchRegexp(".*pipelines.*provided.*one of.*pipelines_dir"))
+			})
+		})
+	})
+
+	Context("when a list of pipelines files is provided instead", func() {
+		BeforeEach(func() {
+			outRequest.Params.Pipelines = nil
+			outRequest.Params.PipelinesFile = []string{"some-file", "products/*/pipelines.yml"}
+		})
+
+		It("returns without error", func() {
+			Expect(validator.ValidateOut(outRequest)).Should(Succeed())
+		})
+
+		Context("when pipelines_dir param is also provided", func() {
+			BeforeEach(func() {
+				outRequest.Params.PipelinesDir = "some-dir"
+			})
+
+			It("returns an error", func() {
+				err := validator.ValidateOut(outRequest)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(MatchRegexp(".*pipelines.*provided.*one of"))
+			})
+		})
+
+		Context("when a pattern is empty", func() {
+			BeforeEach(func() {
+				outRequest.Params.PipelinesFile[1] = ""
+			})
+
+			It("returns an error", func() {
+				err := validator.ValidateOut(outRequest)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(MatchRegexp(".*pattern.*non-empty.*pipelines_file\\[1\\]"))
+			})
+		})
+
+		Context("when a pattern is invalid", func() {
+			BeforeEach(func() {
+				outRequest.Params.PipelinesFile[1] = "products/[/pipelines.yml"
+			})
+
+			It("returns an error", func() {
+				err := validator.ValidateOut(outRequest)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(MatchRegexp(".*pipelines_file\\[1\\].*valid glob pattern"))
+			})
+		})
+	})
+
+	Context("when a shared vars file is empty", func() {
+		BeforeEach(func() {
+			outRequest.Params.VarsFiles = []string{""}
+		})
+
+		It("returns an error", func() {
+			err := validator.ValidateOut(outRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(MatchRegexp(".*vars file.*non-empty.*vars_files\\[0\\]"))
+		})
+	})
+
+	Context("when a var from the environment has no environment variable", func() {
+		BeforeEach(func() {
+			outRequest.Params.VarsFromEnv = map[string]string{"team": ""}
+		})
+
+		It("returns an error", func() {
+			err := validator.ValidateOut(outRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(MatchRegexp(".*environment variable.*non-empty.*vars_from_env.team"))
+		})
+	})
+
+	Context("when a var from the environment of a pipeline has no environment variable", func() {
+		BeforeEach(func() {
+			outRequest.Params.Pipelines[0].VarsFromEnv = map[string]string{"team": ""}
+		})
+
+		It("returns an error", func() {
+			err := validator.ValidateOut(outRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(MatchRegexp(".*environment variable.*non-empty.*pipeline\\[0\\].vars_from_env.team"))
+		})
+	})
+
 	Context("when vars files is present but empty", func() {
 		BeforeEach(func() {
 			outRequest.Params.Pipelines[0].VarsFiles = []string{}
@@ -152,6 +302,19 @@ var _ = Describe("ValidateOut", func() {
 		})
 	})
 
+	Context("when renamed_from is the same as the pipeline name", func() {
+		BeforeEach(func() {
+			outRequest.Params.Pipelines[0].RenamedFrom = outRequest.Params.Pipelines[0].Name
+		})
+
+		It("returns an error", func() {
+			err := validator.ValidateOut(outRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(MatchRegexp(".*renamed_from.*differ.*"))
+		})
+	})
+
 	Context("when team name is not provided in source", func() {
 		BeforeEach(func() {
 			outRequest.Params.Pipelines[0].TeamName = "not-supplied"
@@ -23,6 +23,12 @@ func ValidateTeams(teams []concourse.Team) error {
 		if team.Password == "" && team.Username != "" {
 			return fmt.Errorf("%s must be provided for team: %s", "password", team.Name)
 		}
+
+		for j, v := range team.VarsFiles {
+			if v == "" {
+				return fmt.Errorf("%s must be non-empty for team: %s vars_files[%d]", "vars file", team.Name, j)
+			}
+		}
 	}
 
 	return nil
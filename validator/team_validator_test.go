@@ -80,6 +80,19 @@ var _ = Describe("ValidateTeams", func() {
 		})
 	})
 
+	Context("when a team vars file is empty", func() {
+		BeforeEach(func() {
+			teams[0].VarsFiles = []string{"some-vars.yml", ""}
+		})
+
+		It("returns an error", func() {
+			err := validator.ValidateTeams(teams)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(MatchRegexp(".*vars file.*non-empty.*some team.*vars_files\\[1\\]"))
+		})
+	})
+
 	Context("when there are no teams", func() {
 		It("returns an error", func() {
 			err := validator.ValidateTeams([]concourse.Team{})
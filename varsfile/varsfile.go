@@ -0,0 +1,454 @@
+package varsfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/concourse/concourse-pipeline-resource/concourse"
+	"gopkg.in/yaml.v2"
+)
+
+// Resolve returns the path of a YAML file containing the vars at path. Vars
+// files in JSON, dotenv or properties format, and directories, are converted
+// into a file in tmpDir. Any other file is assumed to be YAML, and its path
+// returned unchanged.
+func Resolve(path string, tmpDir string) (string, error) {
+	vars, err := Read(path)
+	if err != nil {
+		return "", err
+	}
+
+	if vars == nil {
+		return path, nil
+	}
+
+	return write(vars, tmpDir)
+}
+
+// Filepaths returns the paths of the vars files of a pipeline, with the
+// vars files and vars shared with other pipelines before its own vars files,
+// so that fly gives them lower precedence. The vars files and vars of each
+// of shared are passed in order, with its vars written to a file in tmpDir
+// after its vars files. Vars files are relative to sourcesDir, and resolved
+// as by Resolve.
+func Filepaths(
+	shared []concourse.SharedVars,
+	varsFiles []string,
+	sourcesDir string,
+	tmpDir string,
+) ([]string, error) {
+	var filepaths []string
+	appendFiles := func(files []string) error {
+		for _, f := range files {
+			resolved, err := Resolve(filepath.Join(sourcesDir, f), tmpDir)
+			if err != nil {
+				return err
+			}
+			filepaths = append(filepaths, resolved)
+		}
+		return nil
+	}
+
+	for _, s := range shared {
+		err := appendFiles(s.VarsFiles)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(s.Vars) > 0 {
+			varsFilepath, err := write(s.Vars, tmpDir)
+			if err != nil {
+				return nil, err
+			}
+			filepaths = append(filepaths, varsFilepath)
+		}
+	}
+
+	err := appendFiles(varsFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return filepaths, nil
+}
+
+// FromEnv returns the vars with the values of the environment variables
+// they are mapped to. It is an error if an environment variable is not set.
+func FromEnv(varsFromEnv map[string]string) (map[string]interface{}, error) {
+	names := make([]string, 0, len(varsFromEnv))
+	for name := range varsFromEnv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	vars := map[string]interface{}{}
+	for _, name := range names {
+		value, found := os.LookupEnv(varsFromEnv[name])
+		if !found {
+			return nil, fmt.Errorf("environment variable %s for var %s is not set", varsFromEnv[name], name)
+		}
+		vars[name] = value
+	}
+
+	return vars, nil
+}
+
+// Merge merges the vars, with those later taking precedence. It returns nil
+// if there are no vars.
+func Merge(vars ...map[string]interface{}) map[string]interface{} {
+	var merged map[string]interface{}
+	for _, v := range vars {
+		for name, value := range v {
+			if merged == nil {
+				merged = map[string]interface{}{}
+			}
+			merged[name] = value
+		}
+	}
+
+	return merged
+}
+
+// write writes the vars to a YAML file in tmpDir, returning its path.
+func write(vars map[string]interface{}, tmpDir string) (string, error) {
+	b, err := yaml.Marshal(vars)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := ioutil.TempFile(tmpDir, "vars")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	_, err = f.Write(b)
+	if err != nil {
+		// Untested as it is too hard to force a write to a temp file to error
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// Read returns the vars at path according to its format, or nil if it is
+// assumed to be a YAML file. For a directory, each regular file in it is a
+// var whose name is the filename and whose value is the contents of the
+// file, without trailing newlines. Hidden files and subdirectories are
+// ignored.
+func Read(path string) (map[string]interface{}, error) {
+	switch filepath.Ext(path) {
+	case ".json":
+		return readJSON(path)
+	case ".env":
+		return readDotenv(path)
+	case ".properties":
+		return readProperties(path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		// A missing file is left to be reported along with any other invalid
+		// vars file.
+		return nil, nil
+	}
+
+	return readDir(path)
+}
+
+func readJSON(path string) (map[string]interface{}, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var vars map[string]interface{}
+	err = decoder.Decode(&vars)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+
+	if vars == nil {
+		return nil, fmt.Errorf("%s: %s", path, "vars must be an object")
+	}
+
+	return jsonNumbers(vars).(map[string]interface{}), nil
+}
+
+// jsonNumbers replaces each json.Number with an int64, if it is an integer,
+// or a float64, so that it keeps its type when marshalled as YAML.
+func jsonNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = jsonNumbers(e)
+		}
+		return t
+	case []interface{}:
+		for i, e := range t {
+			t[i] = jsonNumbers(e)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+// readDotenv reads KEY=value lines, ignoring blank lines, comments and any
+// export prefix. Values may be quoted, with escape sequences interpreted in
+// double quotes.
+func readDotenv(path string) (map[string]interface{}, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := map[string]interface{}{}
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		sep := strings.Index(line, "=")
+		if sep < 1 {
+			return nil, fmt.Errorf("%s:%d: %s", path, i+1, "expected KEY=value")
+		}
+
+		key := strings.TrimSpace(line[:sep])
+		value := strings.TrimSpace(line[sep+1:])
+
+		switch {
+		case strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'"):
+			end := closingQuote(value)
+			if end < 0 {
+				return nil, fmt.Errorf("%s:%d: %s", path, i+1, "unterminated quoted value")
+			}
+
+			// Only a comment may follow the closing quote.
+			rest := strings.TrimSpace(value[end+1:])
+			if rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("%s:%d: %s", path, i+1, "unexpected characters after quoted value")
+			}
+
+			if value[0] == '"' {
+				value, err = strconv.Unquote(value[:end+1])
+				if err != nil {
+					return nil, fmt.Errorf("%s:%d: %s", path, i+1, err)
+				}
+			} else {
+				value = value[1:end]
+			}
+		default:
+			if comment := strings.Index(value, " #"); comment >= 0 {
+				value = strings.TrimSpace(value[:comment])
+			}
+		}
+
+		vars[key] = value
+	}
+
+	return vars, nil
+}
+
+// closingQuote returns the index of the quote closing the value, which
+// starts with a quote, or -1 if there is none. Quotes escaped with a
+// backslash do not close a double quoted value.
+func closingQuote(value string) int {
+	quote := value[0]
+	for i := 1; i < len(value); i++ {
+		if quote == '"' && value[i] == '\\' {
+			i++
+			continue
+		}
+		if value[i] == quote {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// readProperties reads key=value, key:value or "key value" lines as for
+// java.util.Properties, ignoring blank lines and comments starting with # or
+// !. A line ending with an odd number of backslashes is continued on the next
+// line. Escape sequences, such as \t, \n, \uXXXX and escaped separators, are
+// interpreted in both keys and values.
+func readProperties(path string) (map[string]interface{}, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := map[string]interface{}{}
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		for continued(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+
+		// The key ends at the first unescaped separator, which may be
+		// surrounded by whitespace.
+		sep := 0
+		for sep < len(line) && strings.IndexByte("=: \t\f", line[sep]) < 0 {
+			if line[sep] == '\\' {
+				sep++
+			}
+			sep++
+		}
+		if sep > len(line) {
+			sep = len(line)
+		}
+
+		value := strings.TrimLeft(line[sep:], " \t\f")
+		if strings.HasPrefix(value, "=") || strings.HasPrefix(value, ":") {
+			value = strings.TrimLeft(value[1:], " \t\f")
+		}
+
+		key, err := unescapeProperty(line[:sep])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", path, lineNumber, err)
+		}
+
+		vars[key], err = unescapeProperty(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", path, lineNumber, err)
+		}
+	}
+
+	return vars, nil
+}
+
+// continued returns whether a properties line ends with an odd number of
+// backslashes, the last of which escapes the line break.
+func continued(line string) bool {
+	backslashes := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		backslashes++
+	}
+
+	return backslashes%2 == 1
+}
+
+// unescapeProperty interprets the escape sequences of a properties key or
+// value. A backslash before any other character is dropped, and one at the
+// end, as on the last line of a file, is ignored.
+func unescapeProperty(s string) (string, error) {
+	buf := bytes.NewBuffer(nil)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			buf.WriteByte(c)
+			continue
+		}
+
+		i++
+		if i == len(s) {
+			break
+		}
+
+		switch s[i] {
+		case 't':
+			buf.WriteByte('\t')
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case 'f':
+			buf.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\uXXXX escape in '%s'", s)
+			}
+
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\uXXXX escape in '%s'", s)
+			}
+
+			buf.WriteRune(rune(r))
+			i += 4
+		default:
+			buf.WriteByte(s[i])
+		}
+	}
+
+	return buf.String(), nil
+}
+
+func readDir(path string) (map[string]interface{}, error) {
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := map[string]interface{}{}
+	for _, f := range files {
+		// Hidden files, such as those used by Kubernetes to update mounted
+		// secrets atomically, are not vars.
+		if strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+
+		// Files may be symlinks, as for mounted Kubernetes secrets.
+		filePath := filepath.Join(path, f.Name())
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		vars[f.Name()] = strings.TrimRight(string(b), "\r\n")
+	}
+
+	return vars, nil
+}
+
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+
+	return lines, scanner.Err()
+}
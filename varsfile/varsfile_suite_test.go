@@ -0,0 +1,13 @@
+package varsfile_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestVarsfile(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Varsfile Suite")
+}
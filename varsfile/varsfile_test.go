@@ -0,0 +1,321 @@
+package varsfile_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/concourse-pipeline-resource/concourse"
+	"github.com/concourse/concourse-pipeline-resource/varsfile"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("Varsfile", func() {
+	var dir string
+
+	writeFile := func(path string, contents string) string {
+		fullPath := filepath.Join(dir, path)
+		Expect(os.MkdirAll(filepath.Dir(fullPath), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(fullPath, []byte(contents), 0644)).To(Succeed())
+		return fullPath
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	Describe("Read", func() {
+		It("reads a JSON file", func() {
+			path := writeFile("vars.json", `{"name": "foo", "count": 3, "ratio": 0.5, "enabled": true, "nested": {"list": [1, "two"]}}`)
+
+			vars, err := varsfile.Read(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(vars).To(Equal(map[string]interface{}{
+				"name":    "foo",
+				"count":   int64(3),
+				"ratio":   0.5,
+				"enabled": true,
+				"nested": map[string]interface{}{
+					"list": []interface{}{int64(1), "two"},
+				},
+			}))
+		})
+
+		It("returns an error for JSON which is not an object", func() {
+			path := writeFile("vars.json", `["foo"]`)
+
+			_, err := varsfile.Read(path)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(HavePrefix(path + ": "))
+		})
+
+		It("reads a dotenv file", func() {
+			path := writeFile("vars.env", `
+# a comment
+NAME=foo
+export REGION=eu
+QUOTED="multi\nline"
+SINGLE='not \n escaped'
+COMMENTED=bar # a trailing comment
+QUOTED_COMMENTED="bar" # a trailing comment
+SINGLE_COMMENTED='bar' # a trailing comment
+ESCAPED_QUOTE="a \" b"
+EMPTY=
+`)
+
+			vars, err := varsfile.Read(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(vars).To(Equal(map[string]interface{}{
+				"NAME":             "foo",
+				"REGION":           "eu",
+				"QUOTED":           "multi\nline",
+				"SINGLE":           `not \n escaped`,
+				"COMMENTED":        "bar",
+				"QUOTED_COMMENTED": "bar",
+				"SINGLE_COMMENTED": "bar",
+				"ESCAPED_QUOTE":    `a " b`,
+				"EMPTY":            "",
+			}))
+		})
+
+		It("returns an error for a dotenv line with characters after a quoted value", func() {
+			path := writeFile("vars.env", "NAME=\"foo\" bar\n")
+
+			_, err := varsfile.Read(path)
+			Expect(err).To(MatchError(path + ":1: unexpected characters after quoted value"))
+		})
+
+		It("returns an error for a dotenv line without a value", func() {
+			path := writeFile("vars.env", "NAME=foo\nINVALID\n")
+
+			_, err := varsfile.Read(path)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(Equal(path + ":2: expected KEY=value"))
+		})
+
+		It("reads a properties file", func() {
+			path := writeFile("vars.properties", `
+# a comment
+! another comment
+name=foo
+region: eu
+url = https://example.com
+description a long \
+    description
+path=C:\\vars
+flag
+`)
+
+			vars, err := varsfile.Read(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(vars).To(Equal(map[string]interface{}{
+				"name":        "foo",
+				"region":      "eu",
+				"url":         "https://example.com",
+				"description": "a long description",
+				"path":        `C:\vars`,
+				"flag":        "",
+			}))
+		})
+
+		It("interprets escape sequences in properties keys and values", func() {
+			path := writeFile("vars.properties", `
+a\:b=c
+equals\=sign=d
+key\ with\ spaces = e
+msg=line1\nline2
+tab=a\tb
+unicode=caf\u00e9
+other=\q
+`)
+
+			vars, err := varsfile.Read(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(vars).To(Equal(map[string]interface{}{
+				"a:b":             "c",
+				"equals=sign":     "d",
+				"key with spaces": "e",
+				"msg":             "line1\nline2",
+				"tab":             "a\tb",
+				"unicode":         "café",
+				"other":           "q",
+			}))
+		})
+
+		It("returns an error for a malformed unicode escape in a properties file", func() {
+			path := writeFile("vars.properties", "name=foo\nbad=\\u12\n")
+
+			_, err := varsfile.Read(path)
+			Expect(err).To(MatchError(path + `:2: malformed \uXXXX escape in '\u12'`))
+		})
+
+		It("reads a directory with a var per file", func() {
+			writeFile("vars/username", "admin\n")
+			writeFile("vars/ca.crt", "-----BEGIN CERTIFICATE-----\nabc\n-----END CERTIFICATE-----\n")
+			writeFile("vars/.hidden", "ignored")
+			writeFile("vars/nested/ignored", "ignored")
+			writeFile("secret/password", "s3cret")
+			Expect(os.Symlink(filepath.Join(dir, "secret/password"), filepath.Join(dir, "vars/password"))).To(Succeed())
+
+			vars, err := varsfile.Read(filepath.Join(dir, "vars"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(vars).To(Equal(map[string]interface{}{
+				"username": "admin",
+				"ca.crt":   "-----BEGIN CERTIFICATE-----\nabc\n-----END CERTIFICATE-----",
+				"password": "s3cret",
+			}))
+		})
+
+		It("returns nil for a YAML file", func() {
+			path := writeFile("vars.yml", "name: foo\n")
+
+			vars, err := varsfile.Read(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vars).To(BeNil())
+		})
+	})
+
+	Describe("Resolve", func() {
+		var tmpDir string
+
+		BeforeEach(func() {
+			tmpDir = filepath.Join(dir, "tmp")
+			Expect(os.Mkdir(tmpDir, 0755)).To(Succeed())
+		})
+
+		It("returns the path of a YAML file unchanged", func() {
+			path := writeFile("vars.yml", "name: foo\n")
+
+			resolved, err := varsfile.Resolve(path, tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resolved).To(Equal(path))
+		})
+
+		It("returns the path of a missing file unchanged", func() {
+			path := filepath.Join(dir, "missing.yml")
+
+			resolved, err := varsfile.Resolve(path, tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resolved).To(Equal(path))
+		})
+
+		It("converts other formats into a YAML file in the directory", func() {
+			path := writeFile("vars.json", `{"name": "foo", "count": 3}`)
+
+			resolved, err := varsfile.Resolve(path, tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filepath.Dir(resolved)).To(Equal(tmpDir))
+
+			b, err := ioutil.ReadFile(resolved)
+			Expect(err).NotTo(HaveOccurred())
+
+			var vars map[string]interface{}
+			Expect(yaml.Unmarshal(b, &vars)).To(Succeed())
+			Expect(vars).To(Equal(map[string]interface{}{"name": "foo", "count": 3}))
+		})
+
+		It("returns an error when a file cannot be read", func() {
+			_, err := varsfile.Resolve(filepath.Join(dir, "missing.env"), tmpDir)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Filepaths", func() {
+		var tmpDir string
+
+		BeforeEach(func() {
+			tmpDir = filepath.Join(dir, "tmp")
+			Expect(os.Mkdir(tmpDir, 0755)).To(Succeed())
+
+			writeFile("shared.env", "REGION=eu\n")
+		})
+
+		It("passes the shared vars files and vars before the pipeline's vars files", func() {
+			filepaths, err := varsfile.Filepaths(
+				[]concourse.SharedVars{
+					{VarsFiles: []string{"shared.yml", "shared.env"}, Vars: map[string]interface{}{"slack": "#ci"}},
+					{Vars: map[string]interface{}{}},
+					{VarsFiles: []string{"team.yml"}},
+				},
+				[]string{"pipeline.yml"},
+				dir,
+				tmpDir,
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepaths).To(HaveLen(5))
+			Expect(filepaths[0]).To(Equal(filepath.Join(dir, "shared.yml")))
+			Expect(filepaths[3]).To(Equal(filepath.Join(dir, "team.yml")))
+			Expect(filepaths[4]).To(Equal(filepath.Join(dir, "pipeline.yml")))
+
+			for i, expected := range map[int]string{1: "REGION: eu", 2: "slack: '#ci'"} {
+				Expect(filepath.Dir(filepaths[i])).To(Equal(tmpDir))
+
+				b, err := ioutil.ReadFile(filepaths[i])
+				Expect(err).NotTo(HaveOccurred())
+				Expect(b).To(MatchYAML(expected))
+			}
+		})
+
+		It("returns nil when there are no vars files or vars", func() {
+			filepaths, err := varsfile.Filepaths([]concourse.SharedVars{{}}, nil, dir, tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filepaths).To(BeNil())
+		})
+
+		It("returns an error when a vars file cannot be converted", func() {
+			_, err := varsfile.Filepaths(nil, []string{"missing.env"}, dir, tmpDir)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Merge", func() {
+		It("merges the vars, with those later taking precedence", func() {
+			Expect(varsfile.Merge(
+				map[string]interface{}{"foo": "a", "bar": "a"},
+				nil,
+				map[string]interface{}{"bar": "b"},
+			)).To(Equal(map[string]interface{}{"foo": "a", "bar": "b"}))
+		})
+
+		It("returns nil when there are no vars", func() {
+			Expect(varsfile.Merge(nil, map[string]interface{}{})).To(BeNil())
+		})
+	})
+
+	Describe("FromEnv", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv("SOME_VARSFILE_VAR", "some-value")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv("SOME_VARSFILE_VAR")).To(Succeed())
+		})
+
+		It("returns the vars with the values of their environment variables", func() {
+			vars, err := varsfile.FromEnv(map[string]string{"foo": "SOME_VARSFILE_VAR"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vars).To(Equal(map[string]interface{}{"foo": "some-value"}))
+		})
+
+		It("returns an error when an environment variable is not set", func() {
+			_, err := varsfile.FromEnv(map[string]string{"foo": "SOME_MISSING_VARSFILE_VAR"})
+			Expect(err).To(MatchError("environment variable SOME_MISSING_VARSFILE_VAR for var foo is not set"))
+		})
+	})
+})